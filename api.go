@@ -23,7 +23,7 @@ func (c *Client) GetBotInfo() (*BotInfo, error) {
 	}
 
 	if data.Code != 0 {
-		return nil, newLarkError(data.Code, data.Msg, "GetBotInfo")
+		return nil, newLarkRespError(resp, data.Code, data.Msg, "GetBotInfo")
 	}
 
 	return &data.Bot, nil
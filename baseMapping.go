@@ -0,0 +1,629 @@
+package larki
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+// BaseFieldMarshaler 自定义写入多维表格时的字段值
+type BaseFieldMarshaler interface {
+	MarshalBaseField() (interface{}, error)
+}
+
+// BaseFieldUnmarshaler 自定义从多维表格字段值读取
+type BaseFieldUnmarshaler interface {
+	UnmarshalBaseField(value interface{}) error
+}
+
+// BaseUser 人员、创建人、修改人字段中的用户
+type BaseUser struct {
+	Id        string `json:"id"`
+	Name      string `json:"name,omitempty"`
+	EnName    string `json:"en_name,omitempty"`
+	Email     string `json:"email,omitempty"`
+	AvatarUrl string `json:"avatar_url,omitempty"`
+}
+
+// MarshalBaseField 写入时只需要用户 ID
+func (u BaseUser) MarshalBaseField() (interface{}, error) {
+	return map[string]interface{}{"id": u.Id}, nil
+}
+
+func (BaseUser) baseArrayField() {}
+
+// BaseAttachment 附件字段中的文件
+type BaseAttachment struct {
+	FileToken string `json:"file_token"`
+	Name      string `json:"name,omitempty"`
+	Type      string `json:"type,omitempty"`
+	Size      int    `json:"size,omitempty"`
+	Url       string `json:"url,omitempty"`
+	TmpUrl    string `json:"tmp_url,omitempty"`
+}
+
+// MarshalBaseField 写入时只需要以 bitable_file 或 bitable_image 上传的素材 token
+func (a BaseAttachment) MarshalBaseField() (interface{}, error) {
+	return map[string]interface{}{"file_token": a.FileToken}, nil
+}
+
+func (BaseAttachment) baseArrayField() {}
+
+// BaseUrl 超链接字段
+type BaseUrl struct {
+	Text string `json:"text"`
+	Link string `json:"link"`
+}
+
+// BaseLocation 地理位置字段
+type BaseLocation struct {
+	// Location 经纬度，格式为 "经度,纬度"
+	Location    string `json:"location"`
+	Name        string `json:"name,omitempty"`
+	Address     string `json:"address,omitempty"`
+	FullAddress string `json:"full_address,omitempty"`
+	Pname       string `json:"pname,omitempty"`
+	Cityname    string `json:"cityname,omitempty"`
+	Adname      string `json:"adname,omitempty"`
+}
+
+// MarshalBaseField 写入时只需要经纬度
+func (l BaseLocation) MarshalBaseField() (interface{}, error) {
+	return l.Location, nil
+}
+
+// baseArrayField 字段值为数组的类型，单个值写入时包装为数组
+type baseArrayField interface {
+	baseArrayField()
+}
+
+var (
+	baseMarshalerType   = reflect.TypeOf((*BaseFieldMarshaler)(nil)).Elem()
+	baseUnmarshalerType = reflect.TypeOf((*BaseFieldUnmarshaler)(nil)).Elem()
+	baseArrayFieldType  = reflect.TypeOf((*baseArrayField)(nil)).Elem()
+	timeType            = reflect.TypeOf(time.Time{})
+)
+
+// baseField 结构体中映射到多维表格字段的成员
+type baseField struct {
+	name      string
+	index     []int
+	omitEmpty bool
+	readOnly  bool
+	recordId  bool
+}
+
+var baseFieldCache sync.Map
+
+// baseFieldsOf 解析结构体的 lark 标签，格式为 `lark:"字段名,omitempty,readonly"`
+// 字段名为空时使用成员名，"-" 表示忽略；`lark:",recordid"` 的成员对应记录 ID
+func baseFieldsOf(t reflect.Type) []baseField {
+	if cached, ok := baseFieldCache.Load(t); ok {
+		return cached.([]baseField)
+	}
+
+	var fields []baseField
+	for _, sf := range reflect.VisibleFields(t) {
+		tag, tagged := sf.Tag.Lookup("lark")
+		if !sf.IsExported() || tag == "-" || sf.Anonymous && !tagged {
+			continue
+		}
+
+		parts := strings.Split(tag, ",")
+		field := baseField{name: parts[0], index: sf.Index}
+		if field.name == "" {
+			field.name = sf.Name
+		}
+
+		for _, option := range parts[1:] {
+			switch option {
+			case "omitempty":
+				field.omitEmpty = true
+			case "readonly":
+				field.readOnly = true
+			case "recordid":
+				field.recordId = true
+			}
+		}
+
+		fields = append(fields, field)
+	}
+
+	baseFieldCache.Store(t, fields)
+	return fields
+}
+
+// UnmarshalRecord 将多维表格记录映射到 v 指向的结构体
+// 文本、数字、单选、多选、复选框、日期、人员、附件、超链接、关联、地理位置等字段按目标类型转换，公式和查找引用取其结果
+// 数字写入整数成员时有小数或溢出会返回错误；为 nil 的嵌入结构体指针会被分配
+func UnmarshalRecord(record *larkbitable.AppTableRecord, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("larki: UnmarshalRecord requires a non-nil pointer to struct")
+	}
+
+	rv = rv.Elem()
+	for _, field := range baseFieldsOf(rv.Type()) {
+		value, ok := record.Fields[field.name]
+		if !ok && !field.recordId {
+			continue
+		}
+
+		fv, err := baseFieldValue(rv, field.index)
+		if err != nil {
+			return fmt.Errorf("larki: field %q: %w", field.name, err)
+		}
+
+		if field.recordId {
+			if fv.Kind() == reflect.String {
+				fv.SetString(derefString(record.RecordId))
+			}
+			continue
+		}
+
+		if err = decodeBaseValue(value, fv); err != nil {
+			return fmt.Errorf("larki: field %q: %w", field.name, err)
+		}
+	}
+
+	return nil
+}
+
+// baseFieldValue 按 index 取结构体成员，途经为 nil 的嵌入结构体指针时分配新值
+func baseFieldValue(rv reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && rv.Kind() == reflect.Pointer {
+			if rv.IsNil() {
+				if !rv.CanSet() {
+					return reflect.Value{}, fmt.Errorf("cannot set nil embedded pointer to unexported %s", rv.Type().Elem())
+				}
+				rv.Set(reflect.New(rv.Type().Elem()))
+			}
+			rv = rv.Elem()
+		}
+
+		rv = rv.Field(x)
+	}
+
+	return rv, nil
+}
+
+// MarshalRecord 将结构体转换为写入多维表格的字段，忽略 readonly 和记录 ID 成员
+func MarshalRecord(v interface{}) (map[string]interface{}, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil, errors.New("larki: MarshalRecord requires a struct")
+	}
+
+	fields := make(map[string]interface{})
+	for _, field := range baseFieldsOf(rv.Type()) {
+		if field.readOnly || field.recordId {
+			continue
+		}
+
+		fv, err := rv.FieldByIndexErr(field.index)
+		if err != nil || field.omitEmpty && fv.IsZero() {
+			continue
+		}
+
+		value, err := encodeBaseValue(fv)
+		if err != nil {
+			return nil, fmt.Errorf("larki: field %q: %w", field.name, err)
+		}
+
+		// 人员、附件等字段的值为数组
+		if value != nil && fv.Kind() != reflect.Slice && fv.Type().Implements(baseArrayFieldType) {
+			value = []interface{}{value}
+		}
+
+		fields[field.name] = value
+	}
+
+	return fields, nil
+}
+
+// recordIdOf 返回结构体中记录 ID 成员的值
+func recordIdOf(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return ""
+	}
+
+	for _, field := range baseFieldsOf(rv.Type()) {
+		if field.recordId {
+			if fv, err := rv.FieldByIndexErr(field.index); err == nil && fv.Kind() == reflect.String {
+				return fv.String()
+			}
+		}
+	}
+
+	return ""
+}
+
+// GetRecordAs 获取多维表格记录并映射到 v 指向的结构体
+func (c *Client) GetRecordAs(ctx context.Context, baseId, tableId, recordId string, v interface{}) error {
+	record, err := c.GetRecord(ctx, baseId, tableId, recordId)
+	if err != nil {
+		return err
+	}
+
+	return UnmarshalRecord(record, v)
+}
+
+// GetRecordAs 获取多维表格记录并映射到结构体
+func GetRecordAs(ctx context.Context, baseId, tableId, recordId string, v interface{}) error {
+	return GlobalClient.GetRecordAs(ctx, baseId, tableId, recordId, v)
+}
+
+// GetRecordsAs 获取多维表格记录并映射为 T，limit <= 0 时获取全部，c 为 nil 时使用 GlobalClient
+func GetRecordsAs[T any](ctx context.Context, c *Client, baseId, tableId, viewId string, limit int) ([]T, error) {
+	if c == nil {
+		c = GlobalClient
+	}
+
+	records, err := c.GetRecords(ctx, baseId, tableId, viewId, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]T, len(records))
+	for i, record := range records {
+		if err = UnmarshalRecord(record, &result[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// UpdateRecordFrom 以结构体更新多维表格记录，recordId 为空时使用结构体中的记录 ID 成员
+func (c *Client) UpdateRecordFrom(ctx context.Context, baseId, tableId, recordId string, v interface{}) error {
+	if recordId == "" {
+		recordId = recordIdOf(v)
+	}
+
+	if recordId == "" {
+		return errors.New("larki: UpdateRecordFrom requires a record id")
+	}
+
+	fields, err := MarshalRecord(v)
+	if err != nil {
+		return err
+	}
+
+	return c.UpdateBaseRecord(ctx, baseId, tableId, recordId, fields)
+}
+
+// UpdateRecordFrom 以结构体更新多维表格记录
+func UpdateRecordFrom(ctx context.Context, baseId, tableId, recordId string, v interface{}) error {
+	return GlobalClient.UpdateRecordFrom(ctx, baseId, tableId, recordId, v)
+}
+
+// unwrapBaseValue 取出公式、查找引用字段的结果
+func unwrapBaseValue(value interface{}) interface{} {
+	if m, ok := value.(map[string]interface{}); ok {
+		if inner, ok := m["value"]; ok {
+			if _, typed := m["type"]; typed {
+				return inner
+			}
+		}
+	}
+
+	return value
+}
+
+func decodeBaseValue(value interface{}, rv reflect.Value) error {
+	if value == nil {
+		return nil
+	}
+
+	if rv.CanAddr() && rv.Addr().Type().Implements(baseUnmarshalerType) {
+		return rv.Addr().Interface().(BaseFieldUnmarshaler).UnmarshalBaseField(value)
+	}
+
+	value = unwrapBaseValue(value)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+		return decodeBaseValue(value, rv.Elem())
+	case reflect.Interface:
+		if reflect.TypeOf(value).AssignableTo(rv.Type()) {
+			rv.Set(reflect.ValueOf(value))
+			return nil
+		}
+	case reflect.String:
+		rv.SetString(baseText(value))
+		return nil
+	case reflect.Bool:
+		switch b := value.(type) {
+		case bool:
+			rv.SetBool(b)
+			return nil
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			if err == nil {
+				rv.SetBool(parsed)
+				return nil
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n, ok := baseNumber(value); ok {
+			if n != math.Trunc(n) {
+				return fmt.Errorf("cannot decode fractional number %v into %s", n, rv.Type())
+			}
+
+			// float64 能精确表示的 int64 上界为 2^63
+			if n < math.MinInt64 || n >= -math.MinInt64 || rv.OverflowInt(int64(n)) {
+				return fmt.Errorf("number %v overflows %s", n, rv.Type())
+			}
+
+			rv.SetInt(int64(n))
+			return nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, ok := baseNumber(value); ok {
+			if n != math.Trunc(n) {
+				return fmt.Errorf("cannot decode fractional number %v into %s", n, rv.Type())
+			}
+
+			if n < 0 || n >= 2*-math.MinInt64 || rv.OverflowUint(uint64(n)) {
+				return fmt.Errorf("number %v overflows %s", n, rv.Type())
+			}
+
+			rv.SetUint(uint64(n))
+			return nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if n, ok := baseNumber(value); ok {
+			if rv.OverflowFloat(n) {
+				return fmt.Errorf("number %v overflows %s", n, rv.Type())
+			}
+
+			rv.SetFloat(n)
+			return nil
+		}
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			if n, ok := baseNumber(value); ok {
+				rv.Set(reflect.ValueOf(time.UnixMilli(int64(n))))
+				return nil
+			}
+			break
+		}
+
+		// 人员、附件等数组取第一个
+		if items, ok := value.([]interface{}); ok {
+			if len(items) == 0 {
+				return nil
+			}
+			return decodeBaseValue(items[0], rv)
+		}
+
+		return convertBaseJson(value, rv)
+	case reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.String {
+			strs := baseStrings(value)
+			slice := reflect.MakeSlice(rv.Type(), len(strs), len(strs))
+			for i, s := range strs {
+				slice.Index(i).SetString(s)
+			}
+			rv.Set(slice)
+			return nil
+		}
+
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+
+		slice := reflect.MakeSlice(rv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeBaseValue(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		rv.Set(slice)
+		return nil
+	case reflect.Map:
+		return convertBaseJson(value, rv)
+	}
+
+	return fmt.Errorf("cannot decode %T into %s", value, rv.Type())
+}
+
+// convertBaseJson 通过 JSON 转换为结构体或 map，按目标类型的 json 标签匹配
+func convertBaseJson(value interface{}, rv reflect.Value) error {
+	data, err := sonic.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	target := reflect.New(rv.Type())
+	if err = sonic.Unmarshal(data, target.Interface()); err != nil {
+		return err
+	}
+
+	rv.Set(target.Elem())
+	return nil
+}
+
+func encodeBaseValue(rv reflect.Value) (interface{}, error) {
+	if rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil, nil
+		}
+	}
+
+	if rv.Type().Implements(baseMarshalerType) {
+		return rv.Interface().(BaseFieldMarshaler).MarshalBaseField()
+	}
+
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return encodeBaseValue(rv.Elem())
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), nil
+	case reflect.Struct:
+		if rv.Type() == timeType {
+			t := rv.Interface().(time.Time)
+			if t.IsZero() {
+				return nil, nil
+			}
+			return t.UnixMilli(), nil
+		}
+	case reflect.Slice:
+		if rv.IsNil() {
+			return nil, nil
+		}
+
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			item, err := encodeBaseValue(rv.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return items, nil
+	}
+
+	// 其他类型按 json 标签转换
+	data, err := sonic.Marshal(rv.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err = sonic.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// baseNumber 数字、日期字段及数字字符串
+func baseNumber(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+		return f, err == nil
+	case []interface{}:
+		if len(n) == 1 {
+			return baseNumber(n[0])
+		}
+	}
+
+	return 0, false
+}
+
+// baseText 将字段值转换为文本：富文本拼接各段，多选、人员等以逗号分隔
+func baseText(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case json.Number:
+		return v.String()
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		segments := true
+		for _, item := range v {
+			m, ok := item.(map[string]interface{})
+			if _, typed := m["type"]; !ok || !typed {
+				segments = false
+				break
+			}
+		}
+
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, baseText(item))
+		}
+
+		if segments {
+			return strings.Join(parts, "")
+		}
+		return strings.Join(parts, ",")
+	case map[string]interface{}:
+		for _, key := range []string{"text", "name", "full_address", "link"} {
+			if s, ok := v[key].(string); ok {
+				return s
+			}
+		}
+
+		if ids, ok := v["link_record_ids"]; ok {
+			return baseText(ids)
+		}
+	}
+
+	return fmt.Sprint(value)
+}
+
+// baseStrings 多选、关联、人员等字段转换为字符串列表，对象取 ID 或 token
+func baseStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case []interface{}:
+		strs := make([]string, 0, len(v))
+		for _, item := range v {
+			strs = append(strs, baseStrings(item)...)
+		}
+		return strs
+	case map[string]interface{}:
+		for _, key := range []string{"link_record_ids", "record_ids"} {
+			if ids, ok := v[key]; ok {
+				return baseStrings(ids)
+			}
+		}
+
+		for _, key := range []string{"id", "file_token", "text", "name"} {
+			if s, ok := v[key].(string); ok {
+				return []string{s}
+			}
+		}
+	case nil:
+		return nil
+	}
+
+	return []string{baseText(value)}
+}
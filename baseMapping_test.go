@@ -0,0 +1,308 @@
+package larki
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+)
+
+type baseTestEmbedded struct {
+	Owner string `lark:"负责人"`
+}
+
+type baseTestRecord struct {
+	*baseTestEmbedded
+	*BaseTestExported
+
+	Id          string           `lark:",recordid"`
+	Title       string           `lark:"标题"`
+	Count       int              `lark:"数量"`
+	Small       int8             `lark:"小数量"`
+	Size        uint             `lark:"大小"`
+	Score       float64          `lark:"评分"`
+	Ratio       *float32         `lark:"比例"`
+	Done        bool             `lark:"完成"`
+	Status      string           `lark:"状态"`
+	Tags        []string         `lark:"标签"`
+	User        BaseUser         `lark:"人员"`
+	Users       []BaseUser       `lark:"人员列表"`
+	Due         time.Time        `lark:"截止日期"`
+	Files       []BaseAttachment `lark:"附件"`
+	Link        BaseUrl          `lark:"链接"`
+	Related     []string         `lark:"关联"`
+	Formula     string           `lark:"公式"`
+	FormulaSum  int              `lark:"公式数字"`
+	CreatedTime time.Time        `lark:"创建时间,readonly"`
+	Ignored     string           `lark:"-"`
+}
+
+type BaseTestExported struct {
+	Note string `lark:"备注"`
+}
+
+func TestUnmarshalRecord(t *testing.T) {
+	due := time.UnixMilli(1700000000000)
+	tests := []struct {
+		name    string
+		fields  map[string]interface{}
+		want    func(r *baseTestRecord) interface{}
+		expect  interface{}
+		wantErr bool
+	}{
+		{
+			name:   "text segments",
+			fields: map[string]interface{}{"标题": []interface{}{map[string]interface{}{"type": "text", "text": "Hello "}, map[string]interface{}{"type": "text", "text": "World"}}},
+			want:   func(r *baseTestRecord) interface{} { return r.Title },
+			expect: "Hello World",
+		},
+		{
+			name:   "record id",
+			fields: map[string]interface{}{},
+			want:   func(r *baseTestRecord) interface{} { return r.Id },
+			expect: "rec1",
+		},
+		{
+			name:   "integer",
+			fields: map[string]interface{}{"数量": float64(3)},
+			want:   func(r *baseTestRecord) interface{} { return r.Count },
+			expect: 3,
+		},
+		{
+			name:   "numeric string",
+			fields: map[string]interface{}{"数量": " 12 "},
+			want:   func(r *baseTestRecord) interface{} { return r.Count },
+			expect: 12,
+		},
+		{
+			name:    "fractional into int",
+			fields:  map[string]interface{}{"数量": 1.9},
+			wantErr: true,
+		},
+		{
+			name:    "int8 overflow",
+			fields:  map[string]interface{}{"小数量": float64(200)},
+			wantErr: true,
+		},
+		{
+			name:    "int overflow",
+			fields:  map[string]interface{}{"数量": 1e30},
+			wantErr: true,
+		},
+		{
+			name:    "negative into uint",
+			fields:  map[string]interface{}{"大小": float64(-1)},
+			wantErr: true,
+		},
+		{
+			name:   "float",
+			fields: map[string]interface{}{"评分": 4.5},
+			want:   func(r *baseTestRecord) interface{} { return r.Score },
+			expect: 4.5,
+		},
+		{
+			name:   "float pointer",
+			fields: map[string]interface{}{"比例": 0.5},
+			want:   func(r *baseTestRecord) interface{} { return *r.Ratio },
+			expect: float32(0.5),
+		},
+		{
+			name:    "float32 overflow",
+			fields:  map[string]interface{}{"比例": 1e300},
+			wantErr: true,
+		},
+		{
+			name:   "checkbox",
+			fields: map[string]interface{}{"完成": true},
+			want:   func(r *baseTestRecord) interface{} { return r.Done },
+			expect: true,
+		},
+		{
+			name:   "single select",
+			fields: map[string]interface{}{"状态": "进行中"},
+			want:   func(r *baseTestRecord) interface{} { return r.Status },
+			expect: "进行中",
+		},
+		{
+			name:   "multi select",
+			fields: map[string]interface{}{"标签": []interface{}{"a", "b"}},
+			want:   func(r *baseTestRecord) interface{} { return r.Tags },
+			expect: []string{"a", "b"},
+		},
+		{
+			name:   "person takes first",
+			fields: map[string]interface{}{"人员": []interface{}{map[string]interface{}{"id": "ou_1", "name": "A"}, map[string]interface{}{"id": "ou_2"}}},
+			want:   func(r *baseTestRecord) interface{} { return r.User },
+			expect: BaseUser{Id: "ou_1", Name: "A"},
+		},
+		{
+			name:   "person list",
+			fields: map[string]interface{}{"人员列表": []interface{}{map[string]interface{}{"id": "ou_1"}, map[string]interface{}{"id": "ou_2"}}},
+			want:   func(r *baseTestRecord) interface{} { return r.Users },
+			expect: []BaseUser{{Id: "ou_1"}, {Id: "ou_2"}},
+		},
+		{
+			name:   "date",
+			fields: map[string]interface{}{"截止日期": float64(1700000000000)},
+			want:   func(r *baseTestRecord) interface{} { return r.Due.Equal(due) },
+			expect: true,
+		},
+		{
+			name:   "attachment",
+			fields: map[string]interface{}{"附件": []interface{}{map[string]interface{}{"file_token": "box1", "name": "a.png", "size": float64(10)}}},
+			want:   func(r *baseTestRecord) interface{} { return r.Files },
+			expect: []BaseAttachment{{FileToken: "box1", Name: "a.png", Size: 10}},
+		},
+		{
+			name:   "url",
+			fields: map[string]interface{}{"链接": map[string]interface{}{"text": "site", "link": "https://example.com"}},
+			want:   func(r *baseTestRecord) interface{} { return r.Link },
+			expect: BaseUrl{Text: "site", Link: "https://example.com"},
+		},
+		{
+			name:   "link",
+			fields: map[string]interface{}{"关联": map[string]interface{}{"link_record_ids": []interface{}{"rec1", "rec2"}}},
+			want:   func(r *baseTestRecord) interface{} { return r.Related },
+			expect: []string{"rec1", "rec2"},
+		},
+		{
+			name:   "formula text",
+			fields: map[string]interface{}{"公式": map[string]interface{}{"type": float64(1), "value": []interface{}{map[string]interface{}{"type": "text", "text": "x"}}}},
+			want:   func(r *baseTestRecord) interface{} { return r.Formula },
+			expect: "x",
+		},
+		{
+			name:   "formula number",
+			fields: map[string]interface{}{"公式数字": map[string]interface{}{"type": float64(2), "value": []interface{}{float64(7)}}},
+			want:   func(r *baseTestRecord) interface{} { return r.FormulaSum },
+			expect: 7,
+		},
+		{
+			name:   "embedded pointer",
+			fields: map[string]interface{}{"备注": "note"},
+			want:   func(r *baseTestRecord) interface{} { return r.BaseTestExported != nil && r.Note == "note" },
+			expect: true,
+		},
+		{
+			name:   "embedded pointer untouched without field",
+			fields: map[string]interface{}{"标题": "t"},
+			want:   func(r *baseTestRecord) interface{} { return r.BaseTestExported == nil },
+			expect: true,
+		},
+		{
+			name:    "unexported embedded pointer",
+			fields:  map[string]interface{}{"负责人": "A"},
+			wantErr: true,
+		},
+		{
+			name:   "ignored",
+			fields: map[string]interface{}{"Ignored": "x"},
+			want:   func(r *baseTestRecord) interface{} { return r.Ignored },
+			expect: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recordId := "rec1"
+			record := &larkbitable.AppTableRecord{RecordId: &recordId, Fields: tt.fields}
+
+			var r baseTestRecord
+			err := UnmarshalRecord(record, &r)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("UnmarshalRecord() error = nil, want error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("UnmarshalRecord() error = %v", err)
+			}
+
+			if got := tt.want(&r); !reflect.DeepEqual(got, tt.expect) {
+				t.Errorf("got %#v, want %#v", got, tt.expect)
+			}
+		})
+	}
+}
+
+func TestMarshalRecord(t *testing.T) {
+	ratio := float32(0.5)
+	tests := []struct {
+		name   string
+		record interface{}
+		want   map[string]interface{}
+	}{
+		{
+			name: "scalars",
+			record: struct {
+				Title string   `lark:"标题"`
+				Count int      `lark:"数量"`
+				Size  uint     `lark:"大小"`
+				Ratio *float32 `lark:"比例"`
+				Done  bool     `lark:"完成"`
+			}{"t", 3, 4, &ratio, true},
+			want: map[string]interface{}{"标题": "t", "数量": int64(3), "大小": uint64(4), "比例": float64(0.5), "完成": true},
+		},
+		{
+			name: "record id, readonly and omitempty",
+			record: struct {
+				Id      string `lark:",recordid"`
+				Created string `lark:"创建时间,readonly"`
+				Note    string `lark:"备注,omitempty"`
+				Ignored string `lark:"-"`
+			}{"rec1", "x", "", "y"},
+			want: map[string]interface{}{},
+		},
+		{
+			name: "person and attachment wrapped in array",
+			record: struct {
+				User BaseUser         `lark:"人员"`
+				File BaseAttachment   `lark:"附件"`
+				List []BaseAttachment `lark:"附件列表"`
+			}{BaseUser{Id: "ou_1", Name: "A"}, BaseAttachment{FileToken: "box1", Name: "a"}, []BaseAttachment{{FileToken: "box2"}}},
+			want: map[string]interface{}{
+				"人员":   []interface{}{map[string]interface{}{"id": "ou_1"}},
+				"附件":   []interface{}{map[string]interface{}{"file_token": "box1"}},
+				"附件列表": []interface{}{map[string]interface{}{"file_token": "box2"}},
+			},
+		},
+		{
+			name: "date, url, location and multi select",
+			record: struct {
+				Due      time.Time    `lark:"截止日期"`
+				Empty    time.Time    `lark:"开始日期"`
+				Link     BaseUrl      `lark:"链接"`
+				Location BaseLocation `lark:"位置"`
+				Tags     []string     `lark:"标签"`
+			}{time.UnixMilli(1700000000000), time.Time{}, BaseUrl{Text: "s", Link: "https://example.com"}, BaseLocation{Location: "116.3,39.9", Name: "x"}, []string{"a", "b"}},
+			want: map[string]interface{}{
+				"截止日期": int64(1700000000000),
+				"开始日期": nil,
+				"链接":   map[string]interface{}{"text": "s", "link": "https://example.com"},
+				"位置":   "116.3,39.9",
+				"标签":   []interface{}{"a", "b"},
+			},
+		},
+		{
+			name:   "nil embedded pointer is skipped",
+			record: &struct{ *BaseTestExported }{},
+			want:   map[string]interface{}{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MarshalRecord(tt.record)
+			if err != nil {
+				t.Fatalf("MarshalRecord() error = %v", err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MarshalRecord() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package larki
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+)
+
+type memoryCacheValue struct {
+	value    string
+	expireAt time.Time
+}
+
+type memoryCache struct {
+	m sync.Map
+}
+
+// NewMemoryCache 构造进程内的 TTL 缓存
+func NewMemoryCache() larkcore.Cache {
+	return &memoryCache{}
+}
+
+func (m *memoryCache) Get(ctx context.Context, key string) (string, error) {
+	val, ok := m.m.Load(key)
+	if !ok {
+		return "", nil
+	}
+
+	v := val.(*memoryCacheValue)
+	if !v.expireAt.IsZero() && time.Now().After(v.expireAt) {
+		m.m.Delete(key)
+		return "", nil
+	}
+
+	return v.value, nil
+}
+
+// Set 写入缓存，ttl <= 0 时不过期
+func (m *memoryCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	v := &memoryCacheValue{value: value}
+	if ttl > 0 {
+		v.expireAt = time.Now().Add(ttl)
+	}
+
+	m.m.Store(key, v)
+	return nil
+}
+
+// cacheGet 从联系人缓存读取并反序列化，未启用缓存或未命中时返回 false
+func (c *Client) cacheGet(ctx context.Context, key string, v interface{}) bool {
+	if c.contactCache == nil {
+		return false
+	}
+
+	str, err := c.contactCache.Get(ctx, key)
+	if err != nil || str == "" {
+		return false
+	}
+
+	return sonic.UnmarshalString(str, v) == nil
+}
+
+// cacheSet 序列化后写入联系人缓存，未启用缓存时忽略
+func (c *Client) cacheSet(ctx context.Context, key string, v interface{}) {
+	if c.contactCache == nil {
+		return
+	}
+
+	str, err := sonic.MarshalString(v)
+	if err != nil {
+		return
+	}
+
+	_ = c.contactCache.Set(ctx, key, str, c.contactCacheTTL)
+}
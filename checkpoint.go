@@ -0,0 +1,189 @@
+package larki
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"hash/adler32"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+)
+
+const (
+	uploadKindFile  = "file"
+	uploadKindMedia = "media"
+)
+
+// ErrCheckpointNotFound 断点不存在
+var ErrCheckpointNotFound = errors.New("larki: upload checkpoint not found")
+
+// ErrCheckpointMismatch 本地数据与断点中已上传分片的校验和不一致
+var ErrCheckpointMismatch = errors.New("larki: upload checkpoint checksum mismatch")
+
+// UploadCheckpoint 分片上传断点，记录上传会话和已确认的分片
+type UploadCheckpoint struct {
+	Key        string         `json:"key"`
+	Kind       string         `json:"kind"`
+	Name       string         `json:"name"`
+	ParentType string         `json:"parent_type"`
+	ParentNode string         `json:"parent_node"`
+	Extra      string         `json:"extra,omitempty"`
+	Size       int            `json:"size"`
+	UploadId   string         `json:"upload_id"`
+	BlockSize  int            `json:"block_size"`
+	BlockNum   int            `json:"block_num"`
+	Checksums  map[int]string `json:"checksums"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// blockRange 返回第 seq 个分片的偏移和大小
+func (cp *UploadCheckpoint) blockRange(seq int) (int64, int) {
+	offset := int64(seq) * int64(cp.BlockSize)
+	size := cp.BlockSize
+	if seq == cp.BlockNum-1 {
+		size = cp.Size - seq*cp.BlockSize
+	}
+
+	return offset, size
+}
+
+// Completed 第 seq 个分片是否已上传
+func (cp *UploadCheckpoint) Completed(seq int) bool {
+	_, ok := cp.Checksums[seq]
+	return ok
+}
+
+// CompletedBytes 已上传的字节数
+func (cp *UploadCheckpoint) CompletedBytes() int64 {
+	var n int64
+	for seq := range cp.Checksums {
+		_, size := cp.blockRange(seq)
+		n += int64(size)
+	}
+
+	return n
+}
+
+// CheckpointStore 断点存储
+type CheckpointStore interface {
+	// Load 读取断点，不存在时返回 ErrCheckpointNotFound
+	Load(ctx context.Context, key string) (*UploadCheckpoint, error)
+	Save(ctx context.Context, checkpoint *UploadCheckpoint) error
+	Delete(ctx context.Context, key string) error
+}
+
+type memoryCheckpointStore struct {
+	mu          sync.Mutex
+	checkpoints map[string][]byte
+}
+
+// NewMemoryCheckpointStore 构造进程内的断点存储
+func NewMemoryCheckpointStore() CheckpointStore {
+	return &memoryCheckpointStore{checkpoints: make(map[string][]byte)}
+}
+
+func (s *memoryCheckpointStore) Load(ctx context.Context, key string) (*UploadCheckpoint, error) {
+	s.mu.Lock()
+	data, ok := s.checkpoints[key]
+	s.mu.Unlock()
+	if !ok {
+		return nil, ErrCheckpointNotFound
+	}
+
+	var checkpoint UploadCheckpoint
+	if err := sonic.Unmarshal(data, &checkpoint); err != nil {
+		return nil, err
+	}
+
+	return &checkpoint, nil
+}
+
+func (s *memoryCheckpointStore) Save(ctx context.Context, checkpoint *UploadCheckpoint) error {
+	data, err := sonic.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkpoints[checkpoint.Key] = data
+	return nil
+}
+
+func (s *memoryCheckpointStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checkpoints, key)
+	return nil
+}
+
+type fileCheckpointStore struct {
+	dir string
+}
+
+// NewFileCheckpointStore 构造以 JSON 文件保存在 dir 下的断点存储
+func NewFileCheckpointStore(dir string) (CheckpointStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &fileCheckpointStore{dir: dir}, nil
+}
+
+func (s *fileCheckpointStore) path(key string) string {
+	sum := sha1.Sum([]byte(key))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (s *fileCheckpointStore) Load(ctx context.Context, key string) (*UploadCheckpoint, error) {
+	data, err := os.ReadFile(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrCheckpointNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	var checkpoint UploadCheckpoint
+	if err = sonic.Unmarshal(data, &checkpoint); err != nil {
+		return nil, err
+	}
+
+	return &checkpoint, nil
+}
+
+func (s *fileCheckpointStore) Save(ctx context.Context, checkpoint *UploadCheckpoint) error {
+	data, err := sonic.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+
+	path := s.path(checkpoint.Key)
+	tmp := path + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+func (s *fileCheckpointStore) Delete(ctx context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+// blockChecksum 计算分片的 adler32 校验和
+func blockChecksum(block []byte) string {
+	return strconv.FormatUint(uint64(adler32.Checksum(block)), 10)
+}
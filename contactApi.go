@@ -0,0 +1,438 @@
+package larki
+
+import (
+	"context"
+	"errors"
+
+	larkcontact "github.com/larksuite/oapi-sdk-go/v3/service/contact/v3"
+	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+)
+
+// RootDepartmentId 根部门 ID
+const RootDepartmentId = "0"
+
+// batchGetIdMax 批量查询用户 ID 时单次最多的邮箱/手机号数量
+const batchGetIdMax = 50
+
+const (
+	contactCacheUserKey   = "larki:contact:user:"
+	contactCacheEmailKey  = "larki:contact:email:"
+	contactCacheMobileKey = "larki:contact:mobile:"
+)
+
+// BatchGetUserIds 通过邮箱和手机号批量查询用户 ID，返回 邮箱/手机号 -> 用户 ID 的映射
+// 查询不到的邮箱/手机号不会出现在结果中
+func (c *Client) BatchGetUserIds(ctx context.Context, idType string, emails, mobiles []string) (map[string]string, error) {
+	ids := make(map[string]string, len(emails)+len(mobiles))
+
+	missEmails := make([]string, 0, len(emails))
+	for _, email := range emails {
+		var id string
+		if c.cacheGet(ctx, contactCacheEmailKey+idType+":"+email, &id) {
+			ids[email] = id
+		} else {
+			missEmails = append(missEmails, email)
+		}
+	}
+
+	missMobiles := make([]string, 0, len(mobiles))
+	for _, mobile := range mobiles {
+		var id string
+		if c.cacheGet(ctx, contactCacheMobileKey+idType+":"+mobile, &id) {
+			ids[mobile] = id
+		} else {
+			missMobiles = append(missMobiles, mobile)
+		}
+	}
+
+	for len(missEmails) > 0 || len(missMobiles) > 0 {
+		emailBatch := missEmails[:minInt(len(missEmails), batchGetIdMax)]
+		mobileBatch := missMobiles[:minInt(len(missMobiles), batchGetIdMax)]
+		missEmails = missEmails[len(emailBatch):]
+		missMobiles = missMobiles[len(mobileBatch):]
+
+		body := larkcontact.NewBatchGetIdUserReqBodyBuilder()
+		if len(emailBatch) > 0 {
+			body.Emails(emailBatch)
+		}
+		if len(mobileBatch) > 0 {
+			body.Mobiles(mobileBatch)
+		}
+
+		var resp *larkcontact.BatchGetIdUserResp
+		err := c.retry(ctx, retrySafe, func() (err error) {
+			resp, err = c.Contact.User.BatchGetId(ctx, larkcontact.NewBatchGetIdUserReqBuilder().
+				UserIdType(idType).Body(body.Build()).Build())
+			if err != nil {
+				return err
+			}
+
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "BatchGetUserIds")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, info := range resp.Data.UserList {
+			if info.UserId == nil {
+				continue
+			}
+
+			if info.Email != nil {
+				ids[*info.Email] = *info.UserId
+				c.cacheSet(ctx, contactCacheEmailKey+idType+":"+*info.Email, *info.UserId)
+			}
+
+			if info.Mobile != nil {
+				ids[*info.Mobile] = *info.UserId
+				c.cacheSet(ctx, contactCacheMobileKey+idType+":"+*info.Mobile, *info.UserId)
+			}
+		}
+	}
+
+	return ids, nil
+}
+
+// BatchGetUserIds 通过邮箱和手机号批量查询用户 ID
+func BatchGetUserIds(ctx context.Context, idType string, emails, mobiles []string) (map[string]string, error) {
+	return GlobalClient.BatchGetUserIds(ctx, idType, emails, mobiles)
+}
+
+// GetOpenIdByEmail 通过邮箱查询用户 open_id
+func (c *Client) GetOpenIdByEmail(ctx context.Context, email string) (string, error) {
+	ids, err := c.BatchGetUserIds(ctx, larkcontact.UserIdTypeOpenId, []string{email}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	id, ok := ids[email]
+	if !ok {
+		return "", newNotFoundError("user not found: "+email, "GetOpenIdByEmail")
+	}
+
+	return id, nil
+}
+
+// GetOpenIdByEmail 通过邮箱查询用户 open_id
+func GetOpenIdByEmail(ctx context.Context, email string) (string, error) {
+	return GlobalClient.GetOpenIdByEmail(ctx, email)
+}
+
+// GetOpenIdByMobile 通过手机号查询用户 open_id
+func (c *Client) GetOpenIdByMobile(ctx context.Context, mobile string) (string, error) {
+	ids, err := c.BatchGetUserIds(ctx, larkcontact.UserIdTypeOpenId, nil, []string{mobile})
+	if err != nil {
+		return "", err
+	}
+
+	id, ok := ids[mobile]
+	if !ok {
+		return "", newNotFoundError("user not found: "+mobile, "GetOpenIdByMobile")
+	}
+
+	return id, nil
+}
+
+// GetOpenIdByMobile 通过手机号查询用户 open_id
+func GetOpenIdByMobile(ctx context.Context, mobile string) (string, error) {
+	return GlobalClient.GetOpenIdByMobile(ctx, mobile)
+}
+
+// GetUser 获取用户信息，idType 为 open_id / union_id / user_id
+func (c *Client) GetUser(ctx context.Context, userId, idType string) (*larkcontact.User, error) {
+	key := contactCacheUserKey + idType + ":" + userId
+
+	var user larkcontact.User
+	if c.cacheGet(ctx, key, &user) {
+		return &user, nil
+	}
+
+	var resp *larkcontact.GetUserResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Contact.User.Get(ctx, larkcontact.NewGetUserReqBuilder().
+			UserId(userId).
+			UserIdType(idType).
+			DepartmentIdType(larkcontact.DepartmentIdTypeOpenDepartmentId).
+			Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetUser")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	c.cacheSet(ctx, key, resp.Data.User)
+	return resp.Data.User, nil
+}
+
+// GetUser 获取用户信息
+func GetUser(ctx context.Context, userId, idType string) (*larkcontact.User, error) {
+	return GlobalClient.GetUser(ctx, userId, idType)
+}
+
+// GetUserById 通过消息事件中的用户 ID 获取用户信息
+func (c *Client) GetUserById(ctx context.Context, id *larkim.UserId) (*larkcontact.User, error) {
+	if id == nil {
+		return nil, errors.New("larki: empty user id")
+	}
+
+	switch {
+	case id.OpenId != nil && *id.OpenId != "":
+		return c.GetUser(ctx, *id.OpenId, larkcontact.UserIdTypeOpenId)
+	case id.UnionId != nil && *id.UnionId != "":
+		return c.GetUser(ctx, *id.UnionId, larkcontact.UserIdTypeUnionId)
+	case id.UserId != nil && *id.UserId != "":
+		return c.GetUser(ctx, *id.UserId, larkcontact.UserIdTypeUserId)
+	}
+
+	return nil, errors.New("larki: empty user id")
+}
+
+// GetUserById 通过消息事件中的用户 ID 获取用户信息
+func GetUserById(ctx context.Context, id *larkim.UserId) (*larkcontact.User, error) {
+	return GlobalClient.GetUserById(ctx, id)
+}
+
+// GetMessageSender 获取消息发送者的用户信息
+func (c *Client) GetMessageSender(ctx context.Context, event *MessageEvent) (*larkcontact.User, error) {
+	if event == nil || event.P2MessageReceiveV1Data == nil || event.Sender == nil {
+		return nil, errors.New("larki: message event has no sender")
+	}
+
+	return c.GetUserById(ctx, event.Sender.SenderId)
+}
+
+// GetMessageSender 获取消息发送者的用户信息
+func GetMessageSender(ctx context.Context, event *MessageEvent) (*larkcontact.User, error) {
+	return GlobalClient.GetMessageSender(ctx, event)
+}
+
+// ResolveMentions 获取消息中被 @ 用户的信息，跳过机器人和 @所有人
+func (c *Client) ResolveMentions(ctx context.Context, mentions []*larkim.MentionEvent) ([]*larkcontact.User, error) {
+	users := make([]*larkcontact.User, 0, len(mentions))
+	for _, mention := range mentions {
+		if mention.Id == nil || mention.Id.OpenId == nil || *mention.Id.OpenId == c.BotInfo.OpenID {
+			continue
+		}
+
+		user, err := c.GetUserById(ctx, mention.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	return users, nil
+}
+
+// ResolveMentions 获取消息中被 @ 用户的信息
+func ResolveMentions(ctx context.Context, mentions []*larkim.MentionEvent) ([]*larkcontact.User, error) {
+	return GlobalClient.ResolveMentions(ctx, mentions)
+}
+
+// GetDepartment 获取部门信息
+func (c *Client) GetDepartment(ctx context.Context, departmentId string) (*larkcontact.Department, error) {
+	var resp *larkcontact.GetDepartmentResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Contact.Department.Get(ctx, larkcontact.NewGetDepartmentReqBuilder().
+			DepartmentId(departmentId).
+			DepartmentIdType(larkcontact.DepartmentIdTypeOpenDepartmentId).
+			Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetDepartment")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.Department, nil
+}
+
+// GetDepartment 获取部门信息
+func GetDepartment(ctx context.Context, departmentId string) (*larkcontact.Department, error) {
+	return GlobalClient.GetDepartment(ctx, departmentId)
+}
+
+// IterDepartmentUsers 分页迭代部门的直属成员
+func (c *Client) IterDepartmentUsers(departmentId string, options ...PageOption) *Pager[*larkcontact.User] {
+	return newPager(func(ctx context.Context, pageToken string, pageSize int) ([]*larkcontact.User, string, bool, error) {
+		builder := larkcontact.NewFindByDepartmentUserReqBuilder().
+			DepartmentId(departmentId).
+			DepartmentIdType(larkcontact.DepartmentIdTypeOpenDepartmentId).
+			PageToken(pageToken)
+		if pageSize > 0 {
+			builder.PageSize(pageSize)
+		}
+
+		var resp *larkcontact.FindByDepartmentUserResp
+		err := c.retry(ctx, retrySafe, func() (err error) {
+			resp, err = c.Contact.User.FindByDepartment(ctx, builder.Build())
+			if err != nil {
+				return err
+			}
+
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "ListDepartmentUsers")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, "", false, err
+		}
+
+		return resp.Data.Items, derefString(resp.Data.PageToken), derefBool(resp.Data.HasMore), nil
+	}, 50, options...)
+}
+
+// IterDepartmentUsers 分页迭代部门的直属成员
+func IterDepartmentUsers(departmentId string, options ...PageOption) *Pager[*larkcontact.User] {
+	return GlobalClient.IterDepartmentUsers(departmentId, options...)
+}
+
+// IterChildDepartments 分页迭代子部门，recursive 为 true 时包含所有后代部门
+func (c *Client) IterChildDepartments(departmentId string, recursive bool, options ...PageOption) *Pager[*larkcontact.Department] {
+	return newPager(func(ctx context.Context, pageToken string, pageSize int) ([]*larkcontact.Department, string, bool, error) {
+		builder := larkcontact.NewChildrenDepartmentReqBuilder().
+			DepartmentId(departmentId).
+			DepartmentIdType(larkcontact.DepartmentIdTypeOpenDepartmentId).
+			FetchChild(recursive).
+			PageToken(pageToken)
+		if pageSize > 0 {
+			builder.PageSize(pageSize)
+		}
+
+		var resp *larkcontact.ChildrenDepartmentResp
+		err := c.retry(ctx, retrySafe, func() (err error) {
+			resp, err = c.Contact.Department.Children(ctx, builder.Build())
+			if err != nil {
+				return err
+			}
+
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "ListChildDepartments")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, "", false, err
+		}
+
+		return resp.Data.Items, derefString(resp.Data.PageToken), derefBool(resp.Data.HasMore), nil
+	}, 50, options...)
+}
+
+// IterChildDepartments 分页迭代子部门
+func IterChildDepartments(departmentId string, recursive bool, options ...PageOption) *Pager[*larkcontact.Department] {
+	return GlobalClient.IterChildDepartments(departmentId, recursive, options...)
+}
+
+// ListDepartmentUsers 获取部门成员，recursive 为 true 时包含所有子部门成员，结果按 open_id 去重
+func (c *Client) ListDepartmentUsers(ctx context.Context, departmentId string, recursive bool) ([]*larkcontact.User, error) {
+	departmentIds := []string{departmentId}
+	if recursive {
+		children, err := c.IterChildDepartments(departmentId, true).Collect(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, child := range children {
+			if child.OpenDepartmentId != nil {
+				departmentIds = append(departmentIds, *child.OpenDepartmentId)
+			}
+		}
+	}
+
+	seen := make(map[string]struct{})
+	users := make([]*larkcontact.User, 0)
+	for _, id := range departmentIds {
+		pager := c.IterDepartmentUsers(id)
+		for {
+			user, err := pager.Next(ctx)
+			if errors.Is(err, ErrIteratorDone) {
+				break
+			}
+
+			if err != nil {
+				return nil, err
+			}
+
+			openId := derefString(user.OpenId)
+			if _, ok := seen[openId]; ok && openId != "" {
+				continue
+			}
+			seen[openId] = struct{}{}
+
+			users = append(users, user)
+			c.cacheSet(ctx, contactCacheUserKey+larkcontact.UserIdTypeOpenId+":"+openId, user)
+		}
+	}
+
+	return users, nil
+}
+
+// ListDepartmentUsers 获取部门成员
+func ListDepartmentUsers(ctx context.Context, departmentId string, recursive bool) ([]*larkcontact.User, error) {
+	return GlobalClient.ListDepartmentUsers(ctx, departmentId, recursive)
+}
+
+// GetDepartmentTree 获取以 departmentId 为根的部门树，departmentId 为 RootDepartmentId 时获取整个组织架构
+func (c *Client) GetDepartmentTree(ctx context.Context, departmentId string) (*DepartmentNode, error) {
+	root := &DepartmentNode{}
+	if departmentId != RootDepartmentId {
+		department, err := c.GetDepartment(ctx, departmentId)
+		if err != nil {
+			return nil, err
+		}
+		root.Department = department
+	} else {
+		id := RootDepartmentId
+		root.Department = &larkcontact.Department{OpenDepartmentId: &id}
+	}
+
+	children, err := c.IterChildDepartments(departmentId, true).Collect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := map[string]*DepartmentNode{departmentId: root}
+	for _, child := range children {
+		nodes[derefString(child.OpenDepartmentId)] = &DepartmentNode{Department: child}
+	}
+
+	for _, child := range children {
+		parent, ok := nodes[derefString(child.ParentDepartmentId)]
+		if !ok {
+			parent = root
+		}
+
+		parent.Children = append(parent.Children, nodes[derefString(child.OpenDepartmentId)])
+	}
+
+	return root, nil
+}
+
+// GetDepartmentTree 获取部门树
+func GetDepartmentTree(ctx context.Context, departmentId string) (*DepartmentNode, error) {
+	return GlobalClient.GetDepartmentTree(ctx, departmentId)
+}
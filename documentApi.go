@@ -2,9 +2,7 @@ package larki
 
 import (
 	"context"
-	"fmt"
 	"io"
-	"time"
 
 	larkwiki "github.com/larksuite/oapi-sdk-go/v3/service/wiki/v2"
 
@@ -18,15 +16,23 @@ func (c *Client) UpdateBaseRecord(ctx context.Context, baseId, tableId, recordId
 		AppToken(baseId).TableId(tableId).RecordId(recordId).
 		AppTableRecord(larkbitable.NewAppTableRecordBuilder().Fields(fields).Build()).Build()
 
-	resp, err := c.Bitable.AppTableRecord.Update(ctx, req)
+	var resp *larkbitable.UpdateAppTableRecordResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Bitable.AppTableRecord.Update(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UpdateBaseRecord")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return err
 	}
 
-	if !resp.Success() {
-		return fmt.Errorf("failed to update base record %s/%s/%s: [%d] %s", baseId, tableId, recordId, resp.Code, resp.Msg)
-	}
-
 	return nil
 }
 
@@ -34,45 +40,44 @@ func UpdateBaseRecord(ctx context.Context, baseId, tableId, recordId string, fie
 	return GlobalClient.UpdateBaseRecord(ctx, baseId, tableId, recordId, fields)
 }
 
-func (c *Client) GetRecords(ctx context.Context, baseId, tableId, viewId string, limit int) ([]*larkbitable.AppTableRecord, error) {
-	var records []*larkbitable.AppTableRecord
-	var pageToken string
-	pageSize := 50
-	if limit > 0 && limit < pageSize {
-		pageSize = limit
-		records = make([]*larkbitable.AppTableRecord, 0, limit)
-	} else {
-		records = make([]*larkbitable.AppTableRecord, 0, pageSize)
-	}
-
-	for {
-		req := larkbitable.NewListAppTableRecordReqBuilder().
-			AppToken(baseId).TableId(tableId).ViewId(viewId).PageSize(pageSize).PageToken(pageToken).Build()
-
-		resp, err := c.Bitable.AppTableRecord.List(ctx, req)
-		if err != nil {
-			return nil, err
+// IterRecords 分页迭代多维表格记录
+func (c *Client) IterRecords(baseId, tableId, viewId string, options ...PageOption) *Pager[*larkbitable.AppTableRecord] {
+	return newPager(func(ctx context.Context, pageToken string, pageSize int) ([]*larkbitable.AppTableRecord, string, bool, error) {
+		builder := larkbitable.NewListAppTableRecordReqBuilder().
+			AppToken(baseId).TableId(tableId).ViewId(viewId).PageToken(pageToken)
+		if pageSize > 0 {
+			builder.PageSize(pageSize)
 		}
 
-		if !resp.Success() {
-			return nil, fmt.Errorf("failed to get records %s/%s: [%d] %s", baseId, tableId, resp.Code, resp.Msg)
-		}
+		var resp *larkbitable.ListAppTableRecordResp
+		err := c.retry(ctx, retrySafe, func() (err error) {
+			resp, err = c.Bitable.AppTableRecord.List(ctx, builder.Build())
+			if err != nil {
+				return err
+			}
 
-		records = append(records, resp.Data.Items...)
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetRecords")
+			}
 
-		if resp.Data.HasMore == nil || !*resp.Data.HasMore || resp.Data.PageToken == nil {
-			break
+			return nil
+		})
+		if err != nil {
+			return nil, "", false, err
 		}
 
-		size := len(records)
+		return resp.Data.Items, derefString(resp.Data.PageToken), derefBool(resp.Data.HasMore), nil
+	}, 50, options...)
+}
 
-		if limit > 0 && size >= limit {
-			records = records[:limit]
-			break
-		}
-	}
+// IterRecords 分页迭代多维表格记录
+func IterRecords(baseId, tableId, viewId string, options ...PageOption) *Pager[*larkbitable.AppTableRecord] {
+	return GlobalClient.IterRecords(baseId, tableId, viewId, options...)
+}
 
-	return records, nil
+// GetRecords 获取多维表格记录，limit <= 0 时获取全部
+func (c *Client) GetRecords(ctx context.Context, baseId, tableId, viewId string, limit int) ([]*larkbitable.AppTableRecord, error) {
+	return c.IterRecords(baseId, tableId, viewId, WithPageLimit(limit)).Collect(ctx)
 }
 
 func GetRecords(ctx context.Context, baseId, tableId, viewId string, limit int) ([]*larkbitable.AppTableRecord, error) {
@@ -83,15 +88,23 @@ func (c *Client) GetRecord(ctx context.Context, baseId, tableId, recordId string
 	req := larkbitable.NewGetAppTableRecordReqBuilder().
 		AppToken(baseId).TableId(tableId).RecordId(recordId).Build()
 
-	resp, err := c.Bitable.AppTableRecord.Get(ctx, req)
+	var resp *larkbitable.GetAppTableRecordResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Bitable.AppTableRecord.Get(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetRecord")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if !resp.Success() {
-		return nil, newLarkError(resp.Code, resp.Msg, "GetRecord")
-	}
-
 	return resp.Data.Record, nil
 }
 
@@ -100,15 +113,23 @@ func GetRecord(ctx context.Context, baseId, tableId, recordId string) (*larkbita
 }
 
 func (c *Client) GetDocMedia(ctx context.Context, fileToken string) (io.Reader, string, error) {
-	resp, err := c.Drive.Media.Download(ctx, larkdrive.NewDownloadMediaReqBuilder().FileToken(fileToken).Build())
+	var resp *larkdrive.DownloadMediaResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Drive.Media.Download(ctx, larkdrive.NewDownloadMediaReqBuilder().FileToken(fileToken).Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetDocResource")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return nil, "", err
 	}
 
-	if !resp.Success() {
-		return nil, "", newLarkError(resp.Code, resp.Msg, "GetDocResource")
-	}
-
 	return resp.File, resp.FileName, nil
 }
 
@@ -117,15 +138,23 @@ func GetDocMedia(ctx context.Context, fileToken string) (io.Reader, string, erro
 }
 
 func (c *Client) GetDocFile(ctx context.Context, fileToken string) (io.Reader, string, error) {
-	resp, err := c.Drive.File.Download(ctx, larkdrive.NewDownloadFileReqBuilder().FileToken(fileToken).Build())
+	var resp *larkdrive.DownloadFileResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Drive.File.Download(ctx, larkdrive.NewDownloadFileReqBuilder().FileToken(fileToken).Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetDocResource")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return nil, "", err
 	}
 
-	if !resp.Success() {
-		return nil, "", newLarkError(resp.Code, resp.Msg, "GetDocResource")
-	}
-
 	return resp.File, resp.FileName, nil
 }
 
@@ -133,17 +162,42 @@ func GetDocFile(ctx context.Context, fileToken string) (io.Reader, string, error
 	return GlobalClient.GetDocFile(ctx, fileToken)
 }
 
-func (c *Client) ListBaseTables(ctx context.Context, baseId string) ([]*larkbitable.AppTable, error) {
-	resp, err := c.Bitable.AppTable.List(ctx, larkbitable.NewListAppTableReqBuilder().AppToken(baseId).Build())
-	if err != nil {
-		return nil, err
-	}
+// IterBaseTables 分页迭代多维表格的数据表
+func (c *Client) IterBaseTables(baseId string, options ...PageOption) *Pager[*larkbitable.AppTable] {
+	return newPager(func(ctx context.Context, pageToken string, pageSize int) ([]*larkbitable.AppTable, string, bool, error) {
+		builder := larkbitable.NewListAppTableReqBuilder().AppToken(baseId).PageToken(pageToken)
+		if pageSize > 0 {
+			builder.PageSize(pageSize)
+		}
 
-	if !resp.Success() {
-		return nil, newLarkError(resp.Code, resp.Msg, "ListBaseTables")
-	}
+		var resp *larkbitable.ListAppTableResp
+		err := c.retry(ctx, retrySafe, func() (err error) {
+			resp, err = c.Bitable.AppTable.List(ctx, builder.Build())
+			if err != nil {
+				return err
+			}
 
-	return resp.Data.Items, nil
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "ListBaseTables")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, "", false, err
+		}
+
+		return resp.Data.Items, derefString(resp.Data.PageToken), derefBool(resp.Data.HasMore), nil
+	}, 100, options...)
+}
+
+// IterBaseTables 分页迭代多维表格的数据表
+func IterBaseTables(baseId string, options ...PageOption) *Pager[*larkbitable.AppTable] {
+	return GlobalClient.IterBaseTables(baseId, options...)
+}
+
+func (c *Client) ListBaseTables(ctx context.Context, baseId string) ([]*larkbitable.AppTable, error) {
+	return c.IterBaseTables(baseId).Collect(ctx)
 }
 
 func ListBaseTables(ctx context.Context, baseId string) ([]*larkbitable.AppTable, error) {
@@ -151,22 +205,46 @@ func ListBaseTables(ctx context.Context, baseId string) ([]*larkbitable.AppTable
 }
 
 func (c *Client) UploadDocMedia(ctx context.Context, fileName, parentType, parentNode, extras string, size int, reader io.Reader) (string, error) {
-	resp, err := c.Drive.Media.UploadAll(ctx, larkdrive.NewUploadAllMediaReqBuilder().Body(
-		larkdrive.NewUploadAllMediaReqBodyBuilder().
+	return c.uploadDocMediaAll(ctx, fileName, parentType, parentNode, extras, size, "", reader)
+}
+
+// uploadDocMediaAll 一次上传素材，checksum 为空时不校验
+func (c *Client) uploadDocMediaAll(ctx context.Context, fileName, parentType, parentNode, extras string, size int, checksum string, reader io.Reader) (string, error) {
+	rewind := rewinder(reader)
+	var resp *larkdrive.UploadAllMediaResp
+	err := c.retry(ctx, bodyRetryMode(rewind, retrySafe), func() (err error) {
+		if rewind != nil {
+			if err = rewind(); err != nil {
+				return err
+			}
+		}
+
+		body := larkdrive.NewUploadAllMediaReqBodyBuilder().
 			FileName(fileName).
 			ParentType(parentType).
 			ParentNode(parentNode).
 			File(reader).
 			Extra(extras).
-			Size(size).Build()).Build())
+			Size(size)
+		if checksum != "" {
+			body.Checksum(checksum)
+		}
+
+		resp, err = c.Drive.Media.UploadAll(ctx, larkdrive.NewUploadAllMediaReqBuilder().Body(body.Build()).Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UploadMedia")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return "", err
 	}
 
-	if !resp.Success() {
-		return "", newLarkError(resp.Code, resp.Msg, "UploadMedia")
-	}
-
 	return *resp.Data.FileToken, nil
 }
 
@@ -175,23 +253,31 @@ func UploadDocMedia(ctx context.Context, fileName, parentType, parentNode, extra
 }
 
 func (c *Client) ImportDoc(ctx context.Context, fileExt, fileToken, targetType, fileName string, mountType int, mountKey string) (string, error) {
-	resp, err := c.Drive.ImportTask.Create(ctx, larkdrive.NewCreateImportTaskReqBuilder().
-		ImportTask(larkdrive.NewImportTaskBuilder().
-			FileExtension(fileExt).
-			FileToken(fileToken).
-			Type(targetType).
-			Point(
-				larkdrive.NewImportTaskMountPointBuilder().
-					MountType(mountType).MountKey(mountKey).Build()).
-			FileName(fileName).Build()).Build())
+	var resp *larkdrive.CreateImportTaskResp
+	err := c.retry(ctx, retryRateLimited, func() (err error) {
+		resp, err = c.Drive.ImportTask.Create(ctx, larkdrive.NewCreateImportTaskReqBuilder().
+			ImportTask(larkdrive.NewImportTaskBuilder().
+				FileExtension(fileExt).
+				FileToken(fileToken).
+				Type(targetType).
+				Point(
+					larkdrive.NewImportTaskMountPointBuilder().
+						MountType(mountType).MountKey(mountKey).Build()).
+				FileName(fileName).Build()).Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "ImportDoc")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return "", err
 	}
 
-	if !resp.Success() {
-		return "", newLarkError(resp.Code, resp.Msg, "ImportDoc")
-	}
-
 	return *resp.Data.Ticket, nil
 }
 
@@ -200,15 +286,23 @@ func ImportDoc(ctx context.Context, fileExt, fileToken, targetType, fileName str
 }
 
 func (c *Client) GetImportDocStatus(ctx context.Context, ticket string) (*larkdrive.ImportTask, error) {
-	resp, err := c.Drive.ImportTask.Get(ctx, larkdrive.NewGetImportTaskReqBuilder().Ticket(ticket).Build())
+	var resp *larkdrive.GetImportTaskResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Drive.ImportTask.Get(ctx, larkdrive.NewGetImportTaskReqBuilder().Ticket(ticket).Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetImportDocStatus")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if !resp.Success() {
-		return nil, newLarkError(resp.Code, resp.Msg, "GetImportDocStatus")
-	}
-
 	return resp.Data.Result, nil
 }
 
@@ -217,19 +311,27 @@ func GetImportDocStatus(ctx context.Context, ticket string) (*larkdrive.ImportTa
 }
 
 func (c *Client) MoveDocToWiki(ctx context.Context, spaceId, objType, objToken, parentWikiToken string) (*larkwiki.MoveDocsToWikiSpaceNodeRespData, error) {
-	resp, err := c.Wiki.SpaceNode.MoveDocsToWiki(ctx, larkwiki.NewMoveDocsToWikiSpaceNodeReqBuilder().
-		Body(larkwiki.NewMoveDocsToWikiSpaceNodeReqBodyBuilder().
-			ParentWikiToken(parentWikiToken).
-			ObjToken(objToken).
-			ObjType(objType).Build()).SpaceId(spaceId).Build())
+	var resp *larkwiki.MoveDocsToWikiSpaceNodeResp
+	err := c.retry(ctx, retryRateLimited, func() (err error) {
+		resp, err = c.Wiki.SpaceNode.MoveDocsToWiki(ctx, larkwiki.NewMoveDocsToWikiSpaceNodeReqBuilder().
+			Body(larkwiki.NewMoveDocsToWikiSpaceNodeReqBodyBuilder().
+				ParentWikiToken(parentWikiToken).
+				ObjToken(objToken).
+				ObjType(objType).Build()).SpaceId(spaceId).Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "MoveDocToWiki")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if !resp.Success() {
-		return nil, newLarkError(resp.Code, resp.Msg, "MoveDocToWiki")
-	}
-
 	return resp.Data, nil
 }
 
@@ -238,18 +340,26 @@ func MoveDocToWiki(ctx context.Context, spaceId, objType, objToken, parentWikiTo
 }
 
 func (c *Client) GetMoveDocToWikiStatus(ctx context.Context, taskId string) ([]*larkwiki.MoveResult, error) {
-	resp, err := c.Wiki.Task.Get(ctx, larkwiki.NewGetTaskReqBuilder().
-		TaskId(taskId).
-		TaskType(larkwiki.TaskTypeMove).
-		Build())
+	var resp *larkwiki.GetTaskResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Wiki.Task.Get(ctx, larkwiki.NewGetTaskReqBuilder().
+			TaskId(taskId).
+			TaskType(larkwiki.TaskTypeMove).
+			Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetMoveDocToWikiStatus")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if !resp.Success() {
-		return nil, newLarkError(resp.Code, resp.Msg, "GetMoveDocToWikiStatus")
-	}
-
 	return resp.Data.Task.MoveResult, nil
 }
 
@@ -257,76 +367,81 @@ func GetMoveDocToWikiStatus(ctx context.Context, taskId string) ([]*larkwiki.Mov
 	return GlobalClient.GetMoveDocToWikiStatus(ctx, taskId)
 }
 
-// UploadToWiki 上传文件到知识库
-func (c *Client) UploadToWiki(ctx context.Context,
-	name, ext, docType, spaceId, parentNode string,
-	size int, reader io.Reader,
-) ([]*larkwiki.MoveResult, error) {
-	extras := fmt.Sprintf(`{"file_extension":"%s", "obj_type": "%s"}`, ext, docType)
-	fileToken, err := c.UploadDocMedia(ctx,
-		fmt.Sprintf("%s-%d.%s", name, time.Now().UnixMilli(), ext),
-		"ccm_import_open",
-		"", extras, size, reader)
-	if err != nil {
-		return nil, err
-	}
-
-	ticket, err := c.ImportDoc(ctx, ext, fileToken, docType, name, 1, "")
-	if err != nil {
-		return nil, err
-	}
-
-	var status *larkdrive.ImportTask
-	for {
-		status, err = c.GetImportDocStatus(ctx, ticket)
+// WaitImportDoc 轮询导入任务直到完成，任务失败时返回 *TaskError
+func (c *Client) WaitImportDoc(ctx context.Context, ticket string, options ...PollOption) (*larkdrive.ImportTask, error) {
+	return PollTask(ctx, func(ctx context.Context) (*larkdrive.ImportTask, bool, error) {
+		status, err := c.GetImportDocStatus(ctx, ticket)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
-		if *status.JobStatus == 0 {
-			break
-		} else if *status.JobStatus < 3 {
-			time.Sleep(3 * time.Second)
-		} else {
-			return nil, newLarkError(*status.JobStatus, *status.JobErrorMsg, "UploadToWiki")
+		if status.JobStatus == nil {
+			return status, false, nil
 		}
-	}
 
-	resp, err := c.MoveDocToWiki(ctx, spaceId, docType, *status.Token, parentNode)
-	if err != nil {
-		return nil, err
-	}
-
-	var moveStatus []*larkwiki.MoveResult
-	for {
-		if resp.TaskId == nil {
-			break
+		switch jobStatus := *status.JobStatus; jobStatus {
+		case JobStatusSuccess:
+			return status, true, nil
+		case JobStatusInit, JobStatusProcessing:
+			return status, false, nil
+		default:
+			return status, false, &TaskError{Op: "ImportDoc", TaskId: ticket, Status: jobStatus, Msg: derefString(status.JobErrorMsg)}
 		}
+	}, options...)
+}
 
-		moveStatus, err = c.GetMoveDocToWikiStatus(ctx, *resp.TaskId)
+func WaitImportDoc(ctx context.Context, ticket string, options ...PollOption) (*larkdrive.ImportTask, error) {
+	return GlobalClient.WaitImportDoc(ctx, ticket, options...)
+}
+
+// WaitMoveDocToWiki 轮询移动到知识库的任务直到完成，任务失败时返回 *TaskError
+func (c *Client) WaitMoveDocToWiki(ctx context.Context, taskId string, options ...PollOption) ([]*larkwiki.MoveResult, error) {
+	return PollTask(ctx, func(ctx context.Context) ([]*larkwiki.MoveResult, bool, error) {
+		results, err := c.GetMoveDocToWikiStatus(ctx, taskId)
 		if err != nil {
-			return nil, err
+			return nil, false, err
+		}
+
+		if len(results) == 0 {
+			return results, false, nil
 		}
 
-		if len(moveStatus) > 0 {
-			if *moveStatus[0].Status == 0 {
-				break
-			} else if *moveStatus[0].Status == 1 {
-				time.Sleep(3 * time.Second)
-			} else {
-				return nil, newLarkError(*moveStatus[0].Status, *moveStatus[0].StatusMsg, "UploadToWiki")
+		for _, result := range results {
+			if result.Status == nil {
+				return results, false, nil
+			}
+
+			switch status := *result.Status; status {
+			case 0:
+			case 1:
+				return results, false, nil
+			default:
+				return results, false, &TaskError{Op: "MoveDocToWiki", TaskId: taskId, Status: status, Msg: derefString(result.StatusMsg)}
 			}
 		}
 
-		time.Sleep(3 * time.Second)
-	}
+		return results, true, nil
+	}, options...)
+}
+
+func WaitMoveDocToWiki(ctx context.Context, taskId string, options ...PollOption) ([]*larkwiki.MoveResult, error) {
+	return GlobalClient.WaitMoveDocToWiki(ctx, taskId, options...)
+}
 
-	return moveStatus, nil
+// UploadToWiki 上传文件到知识库，options 控制导入和移动任务的轮询
+// ctx 取消时等待流程停止后再返回，返回后不会再读取 reader
+func (c *Client) UploadToWiki(ctx context.Context,
+	name, ext, docType, spaceId, parentNode string,
+	size int, reader io.Reader, options ...PollOption,
+) ([]*larkwiki.MoveResult, error) {
+	job := c.UploadToWikiAsync(ctx, name, ext, docType, spaceId, parentNode, size, reader, options...)
+	<-job.Done()
+	return job.result()
 }
 
 func UploadToWiki(ctx context.Context,
 	name, ext, docType, spaceId, parentNode string,
-	size int, reader io.Reader,
+	size int, reader io.Reader, options ...PollOption,
 ) ([]*larkwiki.MoveResult, error) {
-	return GlobalClient.UploadToWiki(ctx, name, ext, docType, spaceId, parentNode, size, reader)
+	return GlobalClient.UploadToWiki(ctx, name, ext, docType, spaceId, parentNode, size, reader, options...)
 }
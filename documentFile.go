@@ -1,31 +1,53 @@
 package larki
 
 import (
+	"bytes"
 	"context"
 	"io"
-	"strconv"
-	"time"
 
 	larkdrive "github.com/larksuite/oapi-sdk-go/v3/service/drive/v1"
-	"go.uber.org/ratelimit"
 )
 
 func (c *Client) UploadDocFile(ctx context.Context, name, parentType, parentNode string, size int, reader io.Reader) (string, error) {
-	resp, err := c.Drive.File.UploadAll(ctx, larkdrive.NewUploadAllFileReqBuilder().Body(
-		larkdrive.NewUploadAllFileReqBodyBuilder().
+	return c.uploadDocFileAll(ctx, name, parentType, parentNode, size, "", reader)
+}
+
+// uploadDocFileAll 一次上传文件，checksum 为空时不校验
+func (c *Client) uploadDocFileAll(ctx context.Context, name, parentType, parentNode string, size int, checksum string, reader io.Reader) (string, error) {
+	rewind := rewinder(reader)
+	var resp *larkdrive.UploadAllFileResp
+	err := c.retry(ctx, bodyRetryMode(rewind, retrySafe), func() (err error) {
+		if rewind != nil {
+			if err = rewind(); err != nil {
+				return err
+			}
+		}
+
+		body := larkdrive.NewUploadAllFileReqBodyBuilder().
 			FileName(name).
 			ParentType(parentType).
 			ParentNode(parentNode).
 			File(reader).
-			Size(size).Build()).Build())
+			Size(size)
+		if checksum != "" {
+			body.Checksum(checksum)
+		}
+
+		resp, err = c.Drive.File.UploadAll(ctx, larkdrive.NewUploadAllFileReqBuilder().Body(body.Build()).Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UploadFile")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return "", err
 	}
 
-	if !resp.Success() {
-		return "", newLarkError(resp.Code, resp.Msg, "UploadFile")
-	}
-
 	return *resp.Data.FileToken, nil
 }
 
@@ -33,49 +55,70 @@ func UploadDocFile(ctx context.Context, name, parentType, parentNode string, siz
 	return GlobalClient.UploadDocFile(ctx, name, parentType, parentNode, size, reader)
 }
 
-var (
-	uploadDocFilePrepareLimit = ratelimit.New(2)
-	uploadDocFileCloseLimit   = ratelimit.New(2)
-)
+// UploadDocFileMultiPart 分片上传文件到云空间文件夹
+func (c *Client) UploadDocFileMultiPart(ctx context.Context, name, parentNode string, size int, reader io.Reader, options ...UploadOption) (string, error) {
+	return c.uploadMultiPart(ctx, c.docFileMultipartApi(name, "explorer", parentNode, size), reader, options...)
+}
 
-func (c *Client) UploadDocFileMultiPart(ctx context.Context, name, parentNode string, size int, reader io.Reader) (string, error) {
-	uploadDocFilePrepareLimit.Take()
+// UploadDocFileMultiPartAt 从 io.ReaderAt 并发分片上传文件到云空间文件夹
+func (c *Client) UploadDocFileMultiPartAt(ctx context.Context, name, parentNode string, size int, reader io.ReaderAt, options ...UploadOption) (string, error) {
+	return c.uploadMultiPartAt(ctx, c.docFileMultipartApi(name, "explorer", parentNode, size), reader, options...)
+}
+
+func (c *Client) docFileMultipartApi(name, parentType, parentNode string, size int) multipartApi {
+	return multipartApi{
+		prepare: func(ctx context.Context) (*UploadCheckpoint, error) {
+			return c.uploadDocFilePrepare(ctx, name, parentType, parentNode, size)
+		},
+		part:   c.uploadDocFilePart,
+		finish: c.uploadDocFileFinish,
+	}
+}
+
+func (c *Client) uploadDocFilePrepare(ctx context.Context, name, parentType, parentNode string, size int) (*UploadCheckpoint, error) {
 	req := larkdrive.NewUploadPrepareFileReqBuilder().
 		FileUploadInfo(larkdrive.NewFileUploadInfoBuilder().
 			FileName(name).
-			ParentType("explorer").
+			ParentType(parentType).
 			ParentNode(parentNode).
 			Size(size).
 			Build()).
 		Build()
 
-	resp, err := c.Drive.File.UploadPrepare(ctx, req)
-	if err != nil {
-		return "", err
-	}
-
-	if !resp.Success() {
-		return "", newLarkError(resp.Code, resp.Msg, "UploadFilePrepare")
-	}
-
-	uploadId := *resp.Data.UploadId
-	blockSize := *resp.Data.BlockSize
-	blockNum := *resp.Data.BlockNum
-
-	// Upload blocks
-	for i := 0; i < blockNum; i++ {
-		if i == blockNum-1 {
-			blockSize = size - i*blockSize
+	var resp *larkdrive.UploadPrepareFileResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		if err = c.wait(ctx, ApiDriveUploadPrepare); err != nil {
+			return err
 		}
 
-		reader := io.LimitReader(reader, int64(blockSize))
-
-		err := c.uploadDocFilePart(ctx, uploadId, i, blockSize, reader)
+		resp, err = c.Drive.File.UploadPrepare(ctx, req)
 		if err != nil {
-			return "", err
+			return err
 		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UploadFilePrepare")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	return &UploadCheckpoint{
+		Kind:       uploadKindFile,
+		Name:       name,
+		ParentType: parentType,
+		ParentNode: parentNode,
+		Size:       size,
+		UploadId:   *resp.Data.UploadId,
+		BlockSize:  *resp.Data.BlockSize,
+		BlockNum:   *resp.Data.BlockNum,
+	}, nil
+}
+
+func (c *Client) uploadDocFileFinish(ctx context.Context, uploadId string, blockNum int) (string, error) {
 	closeReq := larkdrive.NewUploadFinishFileReqBuilder().
 		Body(larkdrive.NewUploadFinishFileReqBodyBuilder().
 			UploadId(uploadId).
@@ -84,92 +127,110 @@ func (c *Client) UploadDocFileMultiPart(ctx context.Context, name, parentNode st
 		Build()
 
 	// 发起请求
-	uploadDocFileCloseLimit.Take()
-	closeResp, err := c.Drive.File.UploadFinish(ctx, closeReq)
-	if err != nil {
-		return "", err
-	}
-
-	if !closeResp.Success() {
-		return "", newLarkError(closeResp.Code, closeResp.Msg, "UploadFileFinish")
-	}
-
-	return *closeResp.Data.FileToken, nil
-}
-
-var uploadDocFilePartLimiter = ratelimit.New(5)
+	var closeResp *larkdrive.UploadFinishFileResp
+	err := c.retry(ctx, retryRateLimited, func() (err error) {
+		if err = c.wait(ctx, ApiDriveUploadFinish); err != nil {
+			return err
+		}
 
-func (c *Client) uploadDocFilePart(ctx context.Context, uploadId string, i, blockSize int, reader io.Reader) error {
-	uploadDocFilePartLimiter.Take()
+		closeResp, err = c.Drive.File.UploadFinish(ctx, closeReq)
+		if err != nil {
+			return err
+		}
 
-	req := larkdrive.NewUploadPartFileReqBuilder().
-		Body(larkdrive.NewUploadPartFileReqBodyBuilder().
-			UploadId(uploadId).
-			Seq(i).
-			Size(blockSize).
-			File(reader).
-			Build()).
-		Build()
+		if !closeResp.Success() {
+			return newLarkRespError(closeResp.ApiResp, closeResp.Code, closeResp.Msg, "UploadFileFinish")
+		}
 
-	// 发起请求
-	resp, err := c.Drive.File.UploadPart(ctx, req)
+		return nil
+	})
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	if !resp.Success() {
-		if resp.Code == 99991400 {
-			// frequency limit
-			ratelimitResetStr := resp.Header.Get("x-ogw-ratelimit-reset")
-			ratelimitReset, err := strconv.ParseInt(ratelimitResetStr, 10, 64)
-			if err != nil {
-				return err
-			}
+	return *closeResp.Data.FileToken, nil
+}
 
-			// wait for ratelimit reset
-			time.Sleep(time.Duration(ratelimitReset) * time.Second)
+func (c *Client) uploadDocFilePart(ctx context.Context, uploadId string, i int, block []byte, checksum string) error {
+	return c.retry(ctx, retrySafe, func() error {
+		if err := c.wait(ctx, ApiDriveUploadPart); err != nil {
+			return err
+		}
 
-			// retry
-			return c.uploadDocFilePart(ctx, uploadId, i, blockSize, reader)
+		req := larkdrive.NewUploadPartFileReqBuilder().
+			Body(larkdrive.NewUploadPartFileReqBodyBuilder().
+				UploadId(uploadId).
+				Seq(i).
+				Size(len(block)).
+				Checksum(checksum).
+				File(bytes.NewReader(block)).
+				Build()).
+			Build()
+
+		// 发起请求
+		resp, err := c.Drive.File.UploadPart(ctx, req)
+		if err != nil {
+			return err
 		}
 
-		return newLarkError(resp.Code, resp.Msg, "UploadFilePart")
-	}
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UploadFilePart")
+		}
 
-	return nil
+		return nil
+	})
 }
 
-func UploadDocFileMultiPart(ctx context.Context, name, parentNode string, size int, reader io.Reader) (string, error) {
-	return GlobalClient.UploadDocFileMultiPart(ctx, name, parentNode, size, reader)
+func UploadDocFileMultiPart(ctx context.Context, name, parentNode string, size int, reader io.Reader, options ...UploadOption) (string, error) {
+	return GlobalClient.UploadDocFileMultiPart(ctx, name, parentNode, size, reader, options...)
 }
 
-func (c *Client) ListDriveFolder(ctx context.Context, folderToken string) ([]*larkdrive.File, error) {
-	limiter := ratelimit.New(20)
-	pageToken := ""
-
-	files := make([]*larkdrive.File, 0)
+// UploadDocFileMultiPartAt 从 io.ReaderAt 并发分片上传
+func UploadDocFileMultiPartAt(ctx context.Context, name, parentNode string, size int, reader io.ReaderAt, options ...UploadOption) (string, error) {
+	return GlobalClient.UploadDocFileMultiPartAt(ctx, name, parentNode, size, reader, options...)
+}
 
-	for {
-		limiter.Take()
-		resp, err := c.Drive.File.List(ctx, larkdrive.NewListFileReqBuilder().PageToken(pageToken).FolderToken(folderToken).Build())
-		if err != nil {
-			return nil, err
+// IterDriveFolder 分页迭代云空间文件夹下的文件
+func (c *Client) IterDriveFolder(folderToken string, options ...PageOption) *Pager[*larkdrive.File] {
+	return newPager(func(ctx context.Context, pageToken string, pageSize int) ([]*larkdrive.File, string, bool, error) {
+		builder := larkdrive.NewListFileReqBuilder().PageToken(pageToken).FolderToken(folderToken)
+		if pageSize > 0 {
+			builder.PageSize(pageSize)
 		}
 
-		if !resp.Success() {
-			return nil, newLarkError(resp.Code, resp.Msg, "ListDriveFolder")
-		}
+		var resp *larkdrive.ListFileResp
+		err := c.retry(ctx, retrySafe, func() (err error) {
+			if err = c.wait(ctx, ApiDriveList); err != nil {
+				return err
+			}
 
-		files = append(files, resp.Data.Files...)
+			resp, err = c.Drive.File.List(ctx, builder.Build())
+			if err != nil {
+				return err
+			}
+
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "ListDriveFolder")
+			}
 
-		if !*resp.Data.HasMore {
-			break
+			return nil
+		})
+		if err != nil {
+			return nil, "", false, err
 		}
 
-		pageToken = *resp.Data.NextPageToken
-	}
+		return resp.Data.Files, derefString(resp.Data.NextPageToken), derefBool(resp.Data.HasMore), nil
+	}, 100, options...)
+}
 
-	return files, nil
+// IterDriveFolder 分页迭代云空间文件夹下的文件
+func IterDriveFolder(folderToken string, options ...PageOption) *Pager[*larkdrive.File] {
+	return GlobalClient.IterDriveFolder(folderToken, options...)
+}
+
+// ListDriveFolder 获取云空间文件夹下的全部文件
+func (c *Client) ListDriveFolder(ctx context.Context, folderToken string) ([]*larkdrive.File, error) {
+	return c.IterDriveFolder(folderToken).Collect(ctx)
 }
 
 func ListDriveFolder(ctx context.Context, folderToken string) ([]*larkdrive.File, error) {
@@ -185,15 +246,23 @@ func (c *Client) CreateDriveFolder(ctx context.Context, name, folderToken string
 		Build()
 
 	// 发起请求
-	resp, err := c.Drive.File.CreateFolder(ctx, req)
+	var resp *larkdrive.CreateFolderFileResp
+	err := c.retry(ctx, retryRateLimited, func() (err error) {
+		resp, err = c.Drive.File.CreateFolder(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "CreateDriveFolder")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return "", err
 	}
 
-	if !resp.Success() {
-		return "", newLarkError(resp.Code, resp.Msg, "CreateDriveFolder")
-	}
-
 	return *resp.Data.Token, nil
 }
 
@@ -201,14 +270,27 @@ func CreateDriveFolder(ctx context.Context, name, folderToken string) (string, e
 	return GlobalClient.CreateDriveFolder(ctx, name, folderToken)
 }
 
-var (
-	uploadDocMediaMultiPartLimiter        = ratelimit.New(2)
-	uploadDocMediaMultiPartPrepareLimiter = ratelimit.New(2)
-)
+// UploadDocMediaMultiPart 分片上传素材
+func (c *Client) UploadDocMediaMultiPart(ctx context.Context, name, parentType, parentNode, extra string, size int, reader io.Reader, options ...UploadOption) (string, error) {
+	return c.uploadMultiPart(ctx, c.docMediaMultipartApi(name, parentType, parentNode, extra, size), reader, options...)
+}
+
+// UploadDocMediaMultiPartAt 从 io.ReaderAt 并发分片上传素材
+func (c *Client) UploadDocMediaMultiPartAt(ctx context.Context, name, parentType, parentNode, extra string, size int, reader io.ReaderAt, options ...UploadOption) (string, error) {
+	return c.uploadMultiPartAt(ctx, c.docMediaMultipartApi(name, parentType, parentNode, extra, size), reader, options...)
+}
 
-func (c *Client) UploadDocMediaMultiPart(ctx context.Context, name, parentType, parentNode, extra string, size int, reader io.Reader) (string, error) {
-	uploadDocMediaMultiPartPrepareLimiter.Take()
+func (c *Client) docMediaMultipartApi(name, parentType, parentNode, extra string, size int) multipartApi {
+	return multipartApi{
+		prepare: func(ctx context.Context) (*UploadCheckpoint, error) {
+			return c.uploadDocMediaPrepare(ctx, name, parentType, parentNode, extra, size)
+		},
+		part:   c.uploadDocMediaPart,
+		finish: c.uploadDocMediaFinish,
+	}
+}
 
+func (c *Client) uploadDocMediaPrepare(ctx context.Context, name, parentType, parentNode, extra string, size int) (*UploadCheckpoint, error) {
 	req := larkdrive.NewUploadPrepareMediaReqBuilder().
 		MediaUploadInfo(larkdrive.NewMediaUploadInfoBuilder().
 			FileName(name).
@@ -219,33 +301,41 @@ func (c *Client) UploadDocMediaMultiPart(ctx context.Context, name, parentType,
 			Build()).
 		Build()
 
-	resp, err := c.Drive.Media.UploadPrepare(ctx, req)
-	if err != nil {
-		return "", err
-	}
-
-	if !resp.Success() {
-		return "", newLarkError(resp.Code, resp.Msg, "UploadMediaPrepare")
-	}
-
-	uploadId := *resp.Data.UploadId
-	blockSize := *resp.Data.BlockSize
-	blockNum := *resp.Data.BlockNum
-
-	// Upload blocks
-	for i := 0; i < blockNum; i++ {
-		if i == blockNum-1 {
-			blockSize = size - i*blockSize
+	var resp *larkdrive.UploadPrepareMediaResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		if err = c.wait(ctx, ApiMediaUploadPrepare); err != nil {
+			return err
 		}
 
-		reader := io.LimitReader(reader, int64(blockSize))
-
-		err := c.uploadDocMediaPart(ctx, uploadId, i, blockSize, reader)
+		resp, err = c.Drive.Media.UploadPrepare(ctx, req)
 		if err != nil {
-			return "", err
+			return err
 		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UploadMediaPrepare")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	return &UploadCheckpoint{
+		Kind:       uploadKindMedia,
+		Name:       name,
+		ParentType: parentType,
+		ParentNode: parentNode,
+		Extra:      extra,
+		Size:       size,
+		UploadId:   *resp.Data.UploadId,
+		BlockSize:  *resp.Data.BlockSize,
+		BlockNum:   *resp.Data.BlockNum,
+	}, nil
+}
+
+func (c *Client) uploadDocMediaFinish(ctx context.Context, uploadId string, blockNum int) (string, error) {
 	closeReq := larkdrive.NewUploadFinishMediaReqBuilder().
 		Body(larkdrive.NewUploadFinishMediaReqBodyBuilder().
 			UploadId(uploadId).
@@ -254,59 +344,65 @@ func (c *Client) UploadDocMediaMultiPart(ctx context.Context, name, parentType,
 		Build()
 
 	// 发起请求
-	uploadDocMediaMultiPartLimiter.Take()
-	closeResp, err := c.Drive.Media.UploadFinish(ctx, closeReq)
+	var closeResp *larkdrive.UploadFinishMediaResp
+	err := c.retry(ctx, retryRateLimited, func() (err error) {
+		if err = c.wait(ctx, ApiMediaUploadFinish); err != nil {
+			return err
+		}
+
+		closeResp, err = c.Drive.Media.UploadFinish(ctx, closeReq)
+		if err != nil {
+			return err
+		}
+
+		if !closeResp.Success() {
+			return newLarkRespError(closeResp.ApiResp, closeResp.Code, closeResp.Msg, "UploadMediaFinish")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return "", err
 	}
 
-	if !closeResp.Success() {
-		return "", newLarkError(closeResp.Code, closeResp.Msg, "UploadMediaFinish")
-	}
-
 	return *closeResp.Data.FileToken, nil
 }
 
-func (c *Client) uploadDocMediaPart(ctx context.Context, uploadId string, i, blockSize int, reader io.Reader) error {
-	uploadDocMediaMultiPartLimiter.Take()
-
-	req := larkdrive.NewUploadPartMediaReqBuilder().
-		Body(larkdrive.NewUploadPartMediaReqBodyBuilder().
-			UploadId(uploadId).
-			Seq(i).
-			Size(blockSize).
-			File(reader).
-			Build()).
-		Build()
-
-	// 发起请求
-	resp, err := c.Drive.Media.UploadPart(ctx, req)
-	if err != nil {
-		return err
-	}
-
-	if !resp.Success() {
-		if resp.Code == 99991400 {
-			// frequency limit
-			ratelimitResetStr := resp.Header.Get("x-ogw-ratelimit-reset")
-			ratelimitReset, err := strconv.ParseInt(ratelimitResetStr, 10, 64)
-			if err != nil {
-				return err
-			}
+func (c *Client) uploadDocMediaPart(ctx context.Context, uploadId string, i int, block []byte, checksum string) error {
+	return c.retry(ctx, retrySafe, func() error {
+		if err := c.wait(ctx, ApiMediaUploadPart); err != nil {
+			return err
+		}
 
-			// wait for ratelimit reset
-			time.Sleep(time.Duration(ratelimitReset) * time.Second)
+		req := larkdrive.NewUploadPartMediaReqBuilder().
+			Body(larkdrive.NewUploadPartMediaReqBodyBuilder().
+				UploadId(uploadId).
+				Seq(i).
+				Size(len(block)).
+				Checksum(checksum).
+				File(bytes.NewReader(block)).
+				Build()).
+			Build()
+
+		// 发起请求
+		resp, err := c.Drive.Media.UploadPart(ctx, req)
+		if err != nil {
+			return err
+		}
 
-			// retry
-			return c.uploadDocMediaPart(ctx, uploadId, i, blockSize, reader)
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UploadMediaPart")
 		}
 
-		return newLarkError(resp.Code, resp.Msg, "UploadMediaPart")
-	}
+		return nil
+	})
+}
 
-	return nil
+func UploadDocMediaMultiPart(ctx context.Context, name, parentType, parentNode, extra string, size int, reader io.Reader, options ...UploadOption) (string, error) {
+	return GlobalClient.UploadDocMediaMultiPart(ctx, name, parentType, parentNode, extra, size, reader, options...)
 }
 
-func UploadDocMediaMultiPart(ctx context.Context, name, parentType, parentNode, extra string, size int, reader io.Reader) (string, error) {
-	return GlobalClient.UploadDocMediaMultiPart(ctx, name, parentType, parentNode, extra, size, reader)
+// UploadDocMediaMultiPartAt 从 io.ReaderAt 并发分片上传
+func UploadDocMediaMultiPartAt(ctx context.Context, name, parentType, parentNode, extra string, size int, reader io.ReaderAt, options ...UploadOption) (string, error) {
+	return GlobalClient.UploadDocMediaMultiPartAt(ctx, name, parentType, parentNode, extra, size, reader, options...)
 }
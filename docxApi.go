@@ -0,0 +1,460 @@
+package larki
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+const (
+	// docxLatestRevision 文档的最新版本
+	docxLatestRevision = -1
+	// docxCreateMaxSize 一次创建子 Block 的数量上限
+	docxCreateMaxSize = 50
+	// docxUpdateMaxSize 一次批量更新的数量上限
+	docxUpdateMaxSize = 200
+)
+
+// 更新文本样式时指定的字段
+const (
+	docxStyleFieldDone     = 2
+	docxStyleFieldLanguage = 4
+)
+
+// CreateDocx 在 folderToken 下创建云文档，folderToken 为空时创建在根文件夹
+func (c *Client) CreateDocx(ctx context.Context, folderToken, title string) (*larkdocx.Document, error) {
+	body := larkdocx.NewCreateDocumentReqBodyBuilder().Title(title)
+	if folderToken != "" {
+		body.FolderToken(folderToken)
+	}
+
+	req := larkdocx.NewCreateDocumentReqBuilder().Body(body.Build()).Build()
+
+	var resp *larkdocx.CreateDocumentResp
+	err := c.retry(ctx, retryRateLimited, func() (err error) {
+		resp, err = c.Docx.Document.Create(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "CreateDocx")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.Document, nil
+}
+
+// CreateDocx 在 folderToken 下创建云文档
+func CreateDocx(ctx context.Context, folderToken, title string) (*larkdocx.Document, error) {
+	return GlobalClient.CreateDocx(ctx, folderToken, title)
+}
+
+// GetDocx 获取云文档的标题和版本
+func (c *Client) GetDocx(ctx context.Context, docToken string) (*larkdocx.Document, error) {
+	req := larkdocx.NewGetDocumentReqBuilder().DocumentId(docToken).Build()
+
+	var resp *larkdocx.GetDocumentResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Docx.Document.Get(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetDocx")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.Document, nil
+}
+
+// GetDocx 获取云文档的标题和版本
+func GetDocx(ctx context.Context, docToken string) (*larkdocx.Document, error) {
+	return GlobalClient.GetDocx(ctx, docToken)
+}
+
+// IterDocxBlocks 按文档顺序分页迭代云文档的全部 Block
+func (c *Client) IterDocxBlocks(docToken string, options ...PageOption) *Pager[DocxBlock] {
+	return newPager(func(ctx context.Context, pageToken string, pageSize int) ([]DocxBlock, string, bool, error) {
+		builder := larkdocx.NewListDocumentBlockReqBuilder().
+			DocumentId(docToken).
+			DocumentRevisionId(docxLatestRevision).
+			PageToken(pageToken)
+		if pageSize > 0 {
+			builder.PageSize(pageSize)
+		}
+
+		var resp *larkdocx.ListDocumentBlockResp
+		err := c.retry(ctx, retrySafe, func() (err error) {
+			resp, err = c.Docx.DocumentBlock.List(ctx, builder.Build())
+			if err != nil {
+				return err
+			}
+
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "ListDocxBlocks")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, "", false, err
+		}
+
+		blocks := make([]DocxBlock, 0, len(resp.Data.Items))
+		for _, item := range resp.Data.Items {
+			blocks = append(blocks, newDocxBlock(item))
+		}
+
+		return blocks, derefString(resp.Data.PageToken), derefBool(resp.Data.HasMore), nil
+	}, 500, options...)
+}
+
+// IterDocxBlocks 按文档顺序分页迭代云文档的全部 Block
+func IterDocxBlocks(docToken string, options ...PageOption) *Pager[DocxBlock] {
+	return GlobalClient.IterDocxBlocks(docToken, options...)
+}
+
+// GetDocxBlocks 获取云文档的全部 Block
+func (c *Client) GetDocxBlocks(ctx context.Context, docToken string) ([]DocxBlock, error) {
+	return c.IterDocxBlocks(docToken).Collect(ctx)
+}
+
+// GetDocxBlocks 获取云文档的全部 Block
+func GetDocxBlocks(ctx context.Context, docToken string) ([]DocxBlock, error) {
+	return GlobalClient.GetDocxBlocks(ctx, docToken)
+}
+
+// DocxTree 云文档的 Block 树
+type DocxTree struct {
+	Root   *PageBlock
+	blocks map[string]DocxBlock
+}
+
+// Block 按 ID 查找 Block
+func (t *DocxTree) Block(id string) DocxBlock {
+	return t.blocks[id]
+}
+
+// Children 返回 Block 的子 Block
+func (t *DocxTree) Children(block DocxBlock) []DocxBlock {
+	children := make([]DocxBlock, 0, len(block.Base().Children))
+	for _, id := range block.Base().Children {
+		if child, ok := t.blocks[id]; ok {
+			children = append(children, child)
+		}
+	}
+
+	return children
+}
+
+// GetDocxTree 获取云文档的 Block 树
+func (c *Client) GetDocxTree(ctx context.Context, docToken string) (*DocxTree, error) {
+	blocks, err := c.GetDocxBlocks(ctx, docToken)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &DocxTree{blocks: make(map[string]DocxBlock, len(blocks))}
+	for _, block := range blocks {
+		tree.blocks[block.Base().Id] = block
+		if page, ok := block.(*PageBlock); ok && tree.Root == nil {
+			tree.Root = page
+		}
+	}
+
+	if tree.Root == nil {
+		return nil, newNotFoundError("document has no page block", "GetDocxTree")
+	}
+
+	return tree, nil
+}
+
+// GetDocxTree 获取云文档的 Block 树
+func GetDocxTree(ctx context.Context, docToken string) (*DocxTree, error) {
+	return GlobalClient.GetDocxTree(ctx, docToken)
+}
+
+// CreateDocxBlocks 在 parentId 的第 index 个子 Block 前插入 blocks，index 为 -1 时追加到末尾
+// 返回创建后的 Block；表格会自动创建单元格，单元格和其他容器的内容需要以返回的 ID 再次创建
+func (c *Client) CreateDocxBlocks(ctx context.Context, docToken, parentId string, index int, blocks ...DocxBlock) ([]DocxBlock, error) {
+	created := make([]DocxBlock, 0, len(blocks))
+	for start := 0; start < len(blocks); start += docxCreateMaxSize {
+		batch := blocks[start:minInt(start+docxCreateMaxSize, len(blocks))]
+		children := make([]*larkdocx.Block, 0, len(batch))
+		for _, block := range batch {
+			child := *block.toLark()
+			// 新建的 Block 不能携带 ID 和子节点
+			child.BlockId = nil
+			child.ParentId = nil
+			child.Children = nil
+			children = append(children, &child)
+		}
+
+		req := larkdocx.NewCreateDocumentBlockChildrenReqBuilder().
+			DocumentId(docToken).
+			BlockId(parentId).
+			DocumentRevisionId(docxLatestRevision).
+			ClientToken(newRequestUuid()).
+			Body(larkdocx.NewCreateDocumentBlockChildrenReqBodyBuilder().
+				Children(children).
+				Index(index).
+				Build()).
+			Build()
+
+		var resp *larkdocx.CreateDocumentBlockChildrenResp
+		err := c.retry(ctx, retrySafe, func() (err error) {
+			resp, err = c.Docx.DocumentBlockChildren.Create(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "CreateDocxBlocks")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return created, err
+		}
+
+		for _, child := range resp.Data.Children {
+			created = append(created, newDocxBlock(child))
+		}
+
+		if index >= 0 {
+			index += len(batch)
+		}
+	}
+
+	return created, nil
+}
+
+// CreateDocxBlocks 在 parentId 下插入 blocks
+func CreateDocxBlocks(ctx context.Context, docToken, parentId string, index int, blocks ...DocxBlock) ([]DocxBlock, error) {
+	return GlobalClient.CreateDocxBlocks(ctx, docToken, parentId, index, blocks...)
+}
+
+// DeleteDocxBlocks 删除 parentId 下第 start 到第 end-1 个子 Block
+func (c *Client) DeleteDocxBlocks(ctx context.Context, docToken, parentId string, start, end int) error {
+	req := larkdocx.NewBatchDeleteDocumentBlockChildrenReqBuilder().
+		DocumentId(docToken).
+		BlockId(parentId).
+		DocumentRevisionId(docxLatestRevision).
+		ClientToken(newRequestUuid()).
+		Body(larkdocx.NewBatchDeleteDocumentBlockChildrenReqBodyBuilder().
+			StartIndex(start).
+			EndIndex(end).
+			Build()).
+		Build()
+
+	return c.retry(ctx, retrySafe, func() error {
+		resp, err := c.Docx.DocumentBlockChildren.BatchDelete(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "DeleteDocxBlocks")
+		}
+
+		return nil
+	})
+}
+
+// DeleteDocxBlocks 删除 parentId 下第 start 到第 end-1 个子 Block
+func DeleteDocxBlocks(ctx context.Context, docToken, parentId string, start, end int) error {
+	return GlobalClient.DeleteDocxBlocks(ctx, docToken, parentId, start, end)
+}
+
+// DocxBlockUpdate 对单个 Block 的更新
+type DocxBlockUpdate struct {
+	req *larkdocx.UpdateBlockRequest
+}
+
+// UpdateBlockText 替换文本类 Block 的内容
+func UpdateBlockText(blockId string, elements []Inline) DocxBlockUpdate {
+	return DocxBlockUpdate{req: &larkdocx.UpdateBlockRequest{
+		BlockId:            larkcore.StringPtr(blockId),
+		UpdateTextElements: &larkdocx.UpdateTextElementsRequest{Elements: larkElements(elements)},
+	}}
+}
+
+// UpdateTodoDone 修改待办事项的完成状态
+func UpdateTodoDone(blockId string, done bool) DocxBlockUpdate {
+	return DocxBlockUpdate{req: &larkdocx.UpdateBlockRequest{
+		BlockId: larkcore.StringPtr(blockId),
+		UpdateTextStyle: &larkdocx.UpdateTextStyleRequest{
+			Style:  &larkdocx.TextStyle{Done: larkcore.BoolPtr(done)},
+			Fields: []int{docxStyleFieldDone},
+		},
+	}}
+}
+
+// UpdateCodeLanguage 修改代码块的语言
+func UpdateCodeLanguage(blockId string, language int) DocxBlockUpdate {
+	return DocxBlockUpdate{req: &larkdocx.UpdateBlockRequest{
+		BlockId: larkcore.StringPtr(blockId),
+		UpdateTextStyle: &larkdocx.UpdateTextStyleRequest{
+			Style:  &larkdocx.TextStyle{Language: larkcore.IntPtr(language)},
+			Fields: []int{docxStyleFieldLanguage},
+		},
+	}}
+}
+
+// ReplaceBlockImage 替换图片 Block 的素材
+func ReplaceBlockImage(blockId, token string) DocxBlockUpdate {
+	return DocxBlockUpdate{req: &larkdocx.UpdateBlockRequest{
+		BlockId:      larkcore.StringPtr(blockId),
+		ReplaceImage: &larkdocx.ReplaceImageRequest{Token: larkcore.StringPtr(token)},
+	}}
+}
+
+// InsertTableRow 在表格第 index 行插入新行，-1 表示末尾
+func InsertTableRow(blockId string, index int) DocxBlockUpdate {
+	return DocxBlockUpdate{req: &larkdocx.UpdateBlockRequest{
+		BlockId:        larkcore.StringPtr(blockId),
+		InsertTableRow: &larkdocx.InsertTableRowRequest{RowIndex: larkcore.IntPtr(index)},
+	}}
+}
+
+// InsertTableColumn 在表格第 index 列插入新列，-1 表示末尾
+func InsertTableColumn(blockId string, index int) DocxBlockUpdate {
+	return DocxBlockUpdate{req: &larkdocx.UpdateBlockRequest{
+		BlockId:           larkcore.StringPtr(blockId),
+		InsertTableColumn: &larkdocx.InsertTableColumnRequest{ColumnIndex: larkcore.IntPtr(index)},
+	}}
+}
+
+// DeleteTableRows 删除表格第 start 到第 end-1 行
+func DeleteTableRows(blockId string, start, end int) DocxBlockUpdate {
+	return DocxBlockUpdate{req: &larkdocx.UpdateBlockRequest{
+		BlockId: larkcore.StringPtr(blockId),
+		DeleteTableRows: &larkdocx.DeleteTableRowsRequest{
+			RowStartIndex: larkcore.IntPtr(start),
+			RowEndIndex:   larkcore.IntPtr(end),
+		},
+	}}
+}
+
+// DeleteTableColumns 删除表格第 start 到第 end-1 列
+func DeleteTableColumns(blockId string, start, end int) DocxBlockUpdate {
+	return DocxBlockUpdate{req: &larkdocx.UpdateBlockRequest{
+		BlockId: larkcore.StringPtr(blockId),
+		DeleteTableColumns: &larkdocx.DeleteTableColumnsRequest{
+			ColumnStartIndex: larkcore.IntPtr(start),
+			ColumnEndIndex:   larkcore.IntPtr(end),
+		},
+	}}
+}
+
+// MergeTableCells 合并表格中 [rowStart, rowEnd) x [columnStart, columnEnd) 的单元格
+func MergeTableCells(blockId string, rowStart, rowEnd, columnStart, columnEnd int) DocxBlockUpdate {
+	return DocxBlockUpdate{req: &larkdocx.UpdateBlockRequest{
+		BlockId: larkcore.StringPtr(blockId),
+		MergeTableCells: &larkdocx.MergeTableCellsRequest{
+			RowStartIndex:    larkcore.IntPtr(rowStart),
+			RowEndIndex:      larkcore.IntPtr(rowEnd),
+			ColumnStartIndex: larkcore.IntPtr(columnStart),
+			ColumnEndIndex:   larkcore.IntPtr(columnEnd),
+		},
+	}}
+}
+
+// UpdateDocxBlocks 批量更新 Block，返回更新后的 Block
+func (c *Client) UpdateDocxBlocks(ctx context.Context, docToken string, updates ...DocxBlockUpdate) ([]DocxBlock, error) {
+	updated := make([]DocxBlock, 0, len(updates))
+	for start := 0; start < len(updates); start += docxUpdateMaxSize {
+		batch := updates[start:minInt(start+docxUpdateMaxSize, len(updates))]
+		requests := make([]*larkdocx.UpdateBlockRequest, 0, len(batch))
+		for _, update := range batch {
+			requests = append(requests, update.req)
+		}
+
+		req := larkdocx.NewBatchUpdateDocumentBlockReqBuilder().
+			DocumentId(docToken).
+			DocumentRevisionId(docxLatestRevision).
+			ClientToken(newRequestUuid()).
+			Body(larkdocx.NewBatchUpdateDocumentBlockReqBodyBuilder().Requests(requests).Build()).
+			Build()
+
+		var resp *larkdocx.BatchUpdateDocumentBlockResp
+		err := c.retry(ctx, retrySafe, func() (err error) {
+			resp, err = c.Docx.DocumentBlock.BatchUpdate(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UpdateDocxBlocks")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return updated, err
+		}
+
+		for _, block := range resp.Data.Blocks {
+			updated = append(updated, newDocxBlock(block))
+		}
+	}
+
+	return updated, nil
+}
+
+// UpdateDocxBlocks 批量更新 Block
+func UpdateDocxBlocks(ctx context.Context, docToken string, updates ...DocxBlockUpdate) ([]DocxBlock, error) {
+	return GlobalClient.UpdateDocxBlocks(ctx, docToken, updates...)
+}
+
+// InsertDocxImage 在 parentId 的第 index 个位置插入图片：先创建空图片 Block，再上传素材并关联
+func (c *Client) InsertDocxImage(ctx context.Context, docToken, parentId string, index int, name string, size int, reader io.Reader) (*ImageBlock, error) {
+	created, err := c.CreateDocxBlocks(ctx, docToken, parentId, index, &ImageBlock{})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(created) != 1 {
+		return nil, fmt.Errorf("larki: InsertDocxImage created %d blocks, want 1", len(created))
+	}
+
+	blockId := created[0].Base().Id
+	token, err := c.UploadFile(ctx, name, ParentTypeDocxImage, blockId, size, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := c.UpdateDocxBlocks(ctx, docToken, ReplaceBlockImage(blockId, token))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, block := range updated {
+		if image, ok := block.(*ImageBlock); ok {
+			return image, nil
+		}
+	}
+
+	return &ImageBlock{BlockBase: *created[0].Base(), Token: token}, nil
+}
+
+// InsertDocxImage 在 parentId 的第 index 个位置插入图片
+func InsertDocxImage(ctx context.Context, docToken, parentId string, index int, name string, size int, reader io.Reader) (*ImageBlock, error) {
+	return GlobalClient.InsertDocxImage(ctx, docToken, parentId, index, name, size, reader)
+}
@@ -0,0 +1,626 @@
+package larki
+
+import (
+	"net/url"
+	"strings"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+// DocxBlockType 云文档 Block 类型
+type DocxBlockType int
+
+const (
+	DocxBlockPage           DocxBlockType = 1
+	DocxBlockText           DocxBlockType = 2
+	DocxBlockHeading1       DocxBlockType = 3
+	DocxBlockHeading9       DocxBlockType = 11
+	DocxBlockBullet         DocxBlockType = 12
+	DocxBlockOrdered        DocxBlockType = 13
+	DocxBlockCode           DocxBlockType = 14
+	DocxBlockQuote          DocxBlockType = 15
+	DocxBlockEquation       DocxBlockType = 16
+	DocxBlockTodo           DocxBlockType = 17
+	DocxBlockBitable        DocxBlockType = 18
+	DocxBlockCallout        DocxBlockType = 19
+	DocxBlockDivider        DocxBlockType = 22
+	DocxBlockFile           DocxBlockType = 23
+	DocxBlockGrid           DocxBlockType = 24
+	DocxBlockGridColumn     DocxBlockType = 25
+	DocxBlockImage          DocxBlockType = 27
+	DocxBlockSheet          DocxBlockType = 30
+	DocxBlockTable          DocxBlockType = 31
+	DocxBlockTableCell      DocxBlockType = 32
+	DocxBlockQuoteContainer DocxBlockType = 34
+)
+
+// InlineKind 行内元素类型
+type InlineKind int
+
+const (
+	InlineText InlineKind = iota
+	InlineMentionUser
+	InlineMentionDoc
+	InlineEquation
+	// InlineOther 暂不支持的行内元素，写回时会被忽略
+	InlineOther
+)
+
+// Inline 文本中的行内元素
+type Inline struct {
+	Kind InlineKind
+	// Text 文本或公式内容，提及文档时为文档标题
+	Text string
+
+	Bold          bool
+	Italic        bool
+	Strikethrough bool
+	Underline     bool
+	Code          bool
+	// Link 超链接地址
+	Link string
+
+	// UserId 提及用户的 open_id
+	UserId string
+	// DocToken、DocUrl、DocType 提及的云文档
+	DocToken string
+	DocUrl   string
+	DocType  int
+}
+
+// PlainText 无样式的文本
+func PlainText(text string) Inline {
+	return Inline{Kind: InlineText, Text: text}
+}
+
+// LinkText 带超链接的文本
+func LinkText(text, link string) Inline {
+	return Inline{Kind: InlineText, Text: text, Link: link}
+}
+
+// MentionUserInline @用户
+func MentionUserInline(openId string) Inline {
+	return Inline{Kind: InlineMentionUser, UserId: openId}
+}
+
+// InlinesText 拼接行内元素的纯文本
+func InlinesText(elements []Inline) string {
+	var builder strings.Builder
+	for _, element := range elements {
+		builder.WriteString(element.Text)
+	}
+
+	return builder.String()
+}
+
+// BlockBase 所有 Block 共有的字段
+type BlockBase struct {
+	Id       string
+	ParentId string
+	Children []string
+}
+
+// Base 返回共有字段
+func (b *BlockBase) Base() *BlockBase {
+	return b
+}
+
+// DocxBlock 云文档中的一个 Block
+type DocxBlock interface {
+	Base() *BlockBase
+	Type() DocxBlockType
+	toLark() *larkdocx.Block
+}
+
+// PageBlock 文档根节点
+type PageBlock struct {
+	BlockBase
+	Title []Inline
+}
+
+// TextBlock 段落
+type TextBlock struct {
+	BlockBase
+	Elements []Inline
+}
+
+// HeadingBlock 标题，Level 为 1 到 9，超出范围时按最近的级别处理
+type HeadingBlock struct {
+	BlockBase
+	Level    int
+	Elements []Inline
+}
+
+// ListBlock 列表项，子列表为其 Children
+type ListBlock struct {
+	BlockBase
+	Ordered  bool
+	Elements []Inline
+}
+
+// TodoBlock 待办事项
+type TodoBlock struct {
+	BlockBase
+	Done     bool
+	Elements []Inline
+}
+
+// QuoteBlock 引用
+type QuoteBlock struct {
+	BlockBase
+	Elements []Inline
+}
+
+// CodeBlock 代码块，Language 为飞书的语言枚举
+type CodeBlock struct {
+	BlockBase
+	Language int
+	Wrap     bool
+	Elements []Inline
+}
+
+// CalloutBlock 高亮块，内容为其 Children
+type CalloutBlock struct {
+	BlockBase
+	Emoji           string
+	BackgroundColor int
+	BorderColor     int
+	TextColor       int
+}
+
+// DividerBlock 分割线
+type DividerBlock struct {
+	BlockBase
+}
+
+// ImageBlock 图片，Token 为素材 token
+type ImageBlock struct {
+	BlockBase
+	Token  string
+	Width  int
+	Height int
+}
+
+// TableBlock 表格，Cells 按行优先排列单元格 Block 的 ID
+type TableBlock struct {
+	BlockBase
+	Rows         int
+	Columns      int
+	Cells        []string
+	ColumnWidth  []int
+	HeaderRow    bool
+	HeaderColumn bool
+}
+
+// Cell 返回第 row 行第 column 列的单元格 Block ID
+func (b *TableBlock) Cell(row, column int) string {
+	i := row*b.Columns + column
+	if row < 0 || column < 0 || column >= b.Columns || i >= len(b.Cells) {
+		return ""
+	}
+
+	return b.Cells[i]
+}
+
+// TableCellBlock 表格单元格，内容为其 Children
+type TableCellBlock struct {
+	BlockBase
+}
+
+// QuoteContainerBlock 引用容器，内容为其 Children
+type QuoteContainerBlock struct {
+	BlockBase
+}
+
+// UnknownBlock 暂未映射的 Block，保留原始数据
+type UnknownBlock struct {
+	BlockBase
+	Raw *larkdocx.Block
+}
+
+func (b *PageBlock) Type() DocxBlockType           { return DocxBlockPage }
+func (b *TextBlock) Type() DocxBlockType           { return DocxBlockText }
+func (b *HeadingBlock) Type() DocxBlockType        { return DocxBlockHeading1 + DocxBlockType(b.level()-1) }
+func (b *TodoBlock) Type() DocxBlockType           { return DocxBlockTodo }
+func (b *QuoteBlock) Type() DocxBlockType          { return DocxBlockQuote }
+func (b *CodeBlock) Type() DocxBlockType           { return DocxBlockCode }
+func (b *CalloutBlock) Type() DocxBlockType        { return DocxBlockCallout }
+func (b *DividerBlock) Type() DocxBlockType        { return DocxBlockDivider }
+func (b *ImageBlock) Type() DocxBlockType          { return DocxBlockImage }
+func (b *TableBlock) Type() DocxBlockType          { return DocxBlockTable }
+func (b *TableCellBlock) Type() DocxBlockType      { return DocxBlockTableCell }
+func (b *QuoteContainerBlock) Type() DocxBlockType { return DocxBlockQuoteContainer }
+
+func (b *ListBlock) Type() DocxBlockType {
+	if b.Ordered {
+		return DocxBlockOrdered
+	}
+
+	return DocxBlockBullet
+}
+
+func (b *UnknownBlock) Type() DocxBlockType {
+	if b.Raw == nil || b.Raw.BlockType == nil {
+		return 0
+	}
+
+	return DocxBlockType(*b.Raw.BlockType)
+}
+
+func (b *BlockBase) larkBlock(blockType DocxBlockType) *larkdocx.Block {
+	block := &larkdocx.Block{
+		BlockType: larkcore.IntPtr(int(blockType)),
+		Children:  b.Children,
+	}
+
+	if b.Id != "" {
+		block.BlockId = larkcore.StringPtr(b.Id)
+	}
+
+	if b.ParentId != "" {
+		block.ParentId = larkcore.StringPtr(b.ParentId)
+	}
+
+	return block
+}
+
+func (b *PageBlock) toLark() *larkdocx.Block {
+	block := b.larkBlock(b.Type())
+	block.Page = &larkdocx.Text{Elements: larkElements(b.Title)}
+	return block
+}
+
+func (b *TextBlock) toLark() *larkdocx.Block {
+	block := b.larkBlock(b.Type())
+	block.Text = &larkdocx.Text{Elements: larkElements(b.Elements)}
+	return block
+}
+
+func (b *HeadingBlock) toLark() *larkdocx.Block {
+	block := b.larkBlock(b.Type())
+	text := &larkdocx.Text{Elements: larkElements(b.Elements)}
+	switch b.level() {
+	case 1:
+		block.Heading1 = text
+	case 2:
+		block.Heading2 = text
+	case 3:
+		block.Heading3 = text
+	case 4:
+		block.Heading4 = text
+	case 5:
+		block.Heading5 = text
+	case 6:
+		block.Heading6 = text
+	case 7:
+		block.Heading7 = text
+	case 8:
+		block.Heading8 = text
+	default:
+		block.Heading9 = text
+	}
+
+	return block
+}
+
+// level 限制在 1 到 9 之间的标题级别
+func (b *HeadingBlock) level() int {
+	switch {
+	case b.Level < 1:
+		return 1
+	case b.Level > 9:
+		return 9
+	}
+
+	return b.Level
+}
+
+func (b *ListBlock) toLark() *larkdocx.Block {
+	block := b.larkBlock(b.Type())
+	text := &larkdocx.Text{Elements: larkElements(b.Elements)}
+	if b.Ordered {
+		block.Ordered = text
+	} else {
+		block.Bullet = text
+	}
+
+	return block
+}
+
+func (b *TodoBlock) toLark() *larkdocx.Block {
+	block := b.larkBlock(b.Type())
+	block.Todo = &larkdocx.Text{
+		Elements: larkElements(b.Elements),
+		Style:    &larkdocx.TextStyle{Done: larkcore.BoolPtr(b.Done)},
+	}
+	return block
+}
+
+func (b *QuoteBlock) toLark() *larkdocx.Block {
+	block := b.larkBlock(b.Type())
+	block.Quote = &larkdocx.Text{Elements: larkElements(b.Elements)}
+	return block
+}
+
+func (b *CodeBlock) toLark() *larkdocx.Block {
+	block := b.larkBlock(b.Type())
+	style := &larkdocx.TextStyle{Wrap: larkcore.BoolPtr(b.Wrap)}
+	if b.Language > 0 {
+		style.Language = larkcore.IntPtr(b.Language)
+	}
+
+	block.Code = &larkdocx.Text{Elements: larkElements(b.Elements), Style: style}
+	return block
+}
+
+func (b *CalloutBlock) toLark() *larkdocx.Block {
+	block := b.larkBlock(b.Type())
+	block.Callout = &larkdocx.Callout{}
+	if b.Emoji != "" {
+		block.Callout.EmojiId = larkcore.StringPtr(b.Emoji)
+	}
+
+	if b.BackgroundColor > 0 {
+		block.Callout.BackgroundColor = larkcore.IntPtr(b.BackgroundColor)
+	}
+
+	if b.BorderColor > 0 {
+		block.Callout.BorderColor = larkcore.IntPtr(b.BorderColor)
+	}
+
+	if b.TextColor > 0 {
+		block.Callout.TextColor = larkcore.IntPtr(b.TextColor)
+	}
+
+	return block
+}
+
+func (b *DividerBlock) toLark() *larkdocx.Block {
+	block := b.larkBlock(b.Type())
+	block.Divider = &larkdocx.Divider{}
+	return block
+}
+
+func (b *ImageBlock) toLark() *larkdocx.Block {
+	block := b.larkBlock(b.Type())
+	block.Image = &larkdocx.Image{}
+	if b.Token != "" {
+		block.Image.Token = larkcore.StringPtr(b.Token)
+	}
+
+	if b.Width > 0 {
+		block.Image.Width = larkcore.IntPtr(b.Width)
+	}
+
+	if b.Height > 0 {
+		block.Image.Height = larkcore.IntPtr(b.Height)
+	}
+
+	return block
+}
+
+func (b *TableBlock) toLark() *larkdocx.Block {
+	block := b.larkBlock(b.Type())
+	block.Table = &larkdocx.Table{
+		Cells: b.Cells,
+		Property: &larkdocx.TableProperty{
+			RowSize:      larkcore.IntPtr(b.Rows),
+			ColumnSize:   larkcore.IntPtr(b.Columns),
+			ColumnWidth:  b.ColumnWidth,
+			HeaderRow:    larkcore.BoolPtr(b.HeaderRow),
+			HeaderColumn: larkcore.BoolPtr(b.HeaderColumn),
+		},
+	}
+	return block
+}
+
+func (b *TableCellBlock) toLark() *larkdocx.Block {
+	block := b.larkBlock(b.Type())
+	block.TableCell = &larkdocx.TableCell{}
+	return block
+}
+
+func (b *QuoteContainerBlock) toLark() *larkdocx.Block {
+	block := b.larkBlock(b.Type())
+	block.QuoteContainer = &larkdocx.QuoteContainer{}
+	return block
+}
+
+func (b *UnknownBlock) toLark() *larkdocx.Block {
+	return b.Raw
+}
+
+// newDocxBlock 将 SDK 的 Block 转换为对应的类型
+func newDocxBlock(raw *larkdocx.Block) DocxBlock {
+	base := BlockBase{
+		Id:       derefString(raw.BlockId),
+		ParentId: derefString(raw.ParentId),
+		Children: raw.Children,
+	}
+
+	blockType := DocxBlockType(0)
+	if raw.BlockType != nil {
+		blockType = DocxBlockType(*raw.BlockType)
+	}
+
+	switch {
+	case blockType == DocxBlockPage && raw.Page != nil:
+		return &PageBlock{BlockBase: base, Title: inlinesOf(raw.Page)}
+	case blockType == DocxBlockText && raw.Text != nil:
+		return &TextBlock{BlockBase: base, Elements: inlinesOf(raw.Text)}
+	case blockType >= DocxBlockHeading1 && blockType <= DocxBlockHeading9:
+		if text := headingText(raw, blockType); text != nil {
+			return &HeadingBlock{BlockBase: base, Level: int(blockType-DocxBlockHeading1) + 1, Elements: inlinesOf(text)}
+		}
+	case blockType == DocxBlockBullet && raw.Bullet != nil:
+		return &ListBlock{BlockBase: base, Elements: inlinesOf(raw.Bullet)}
+	case blockType == DocxBlockOrdered && raw.Ordered != nil:
+		return &ListBlock{BlockBase: base, Ordered: true, Elements: inlinesOf(raw.Ordered)}
+	case blockType == DocxBlockTodo && raw.Todo != nil:
+		block := &TodoBlock{BlockBase: base, Elements: inlinesOf(raw.Todo)}
+		if raw.Todo.Style != nil {
+			block.Done = derefBool(raw.Todo.Style.Done)
+		}
+		return block
+	case blockType == DocxBlockQuote && raw.Quote != nil:
+		return &QuoteBlock{BlockBase: base, Elements: inlinesOf(raw.Quote)}
+	case blockType == DocxBlockCode && raw.Code != nil:
+		block := &CodeBlock{BlockBase: base, Elements: inlinesOf(raw.Code)}
+		if raw.Code.Style != nil {
+			block.Language = derefInt(raw.Code.Style.Language)
+			block.Wrap = derefBool(raw.Code.Style.Wrap)
+		}
+		return block
+	case blockType == DocxBlockCallout && raw.Callout != nil:
+		return &CalloutBlock{
+			BlockBase:       base,
+			Emoji:           derefString(raw.Callout.EmojiId),
+			BackgroundColor: derefInt(raw.Callout.BackgroundColor),
+			BorderColor:     derefInt(raw.Callout.BorderColor),
+			TextColor:       derefInt(raw.Callout.TextColor),
+		}
+	case blockType == DocxBlockDivider:
+		return &DividerBlock{BlockBase: base}
+	case blockType == DocxBlockImage && raw.Image != nil:
+		return &ImageBlock{
+			BlockBase: base,
+			Token:     derefString(raw.Image.Token),
+			Width:     derefInt(raw.Image.Width),
+			Height:    derefInt(raw.Image.Height),
+		}
+	case blockType == DocxBlockTable && raw.Table != nil:
+		block := &TableBlock{BlockBase: base, Cells: raw.Table.Cells}
+		if property := raw.Table.Property; property != nil {
+			block.Rows = derefInt(property.RowSize)
+			block.Columns = derefInt(property.ColumnSize)
+			block.ColumnWidth = property.ColumnWidth
+			block.HeaderRow = derefBool(property.HeaderRow)
+			block.HeaderColumn = derefBool(property.HeaderColumn)
+		}
+		return block
+	case blockType == DocxBlockTableCell:
+		return &TableCellBlock{BlockBase: base}
+	case blockType == DocxBlockQuoteContainer:
+		return &QuoteContainerBlock{BlockBase: base}
+	}
+
+	return &UnknownBlock{BlockBase: base, Raw: raw}
+}
+
+func headingText(raw *larkdocx.Block, blockType DocxBlockType) *larkdocx.Text {
+	switch blockType - DocxBlockHeading1 + 1 {
+	case 1:
+		return raw.Heading1
+	case 2:
+		return raw.Heading2
+	case 3:
+		return raw.Heading3
+	case 4:
+		return raw.Heading4
+	case 5:
+		return raw.Heading5
+	case 6:
+		return raw.Heading6
+	case 7:
+		return raw.Heading7
+	case 8:
+		return raw.Heading8
+	case 9:
+		return raw.Heading9
+	}
+
+	return nil
+}
+
+// inlinesOf 转换 SDK 的文本元素
+func inlinesOf(text *larkdocx.Text) []Inline {
+	elements := make([]Inline, 0, len(text.Elements))
+	for _, element := range text.Elements {
+		var inline Inline
+		var style *larkdocx.TextElementStyle
+
+		switch {
+		case element.TextRun != nil:
+			inline = Inline{Kind: InlineText, Text: derefString(element.TextRun.Content)}
+			style = element.TextRun.TextElementStyle
+		case element.MentionUser != nil:
+			inline = Inline{Kind: InlineMentionUser, UserId: derefString(element.MentionUser.UserId)}
+			style = element.MentionUser.TextElementStyle
+		case element.MentionDoc != nil:
+			docUrl, _ := url.QueryUnescape(derefString(element.MentionDoc.Url))
+			inline = Inline{
+				Kind:     InlineMentionDoc,
+				Text:     derefString(element.MentionDoc.Title),
+				DocToken: derefString(element.MentionDoc.Token),
+				DocUrl:   docUrl,
+				DocType:  derefInt(element.MentionDoc.ObjType),
+			}
+			style = element.MentionDoc.TextElementStyle
+		case element.Equation != nil:
+			inline = Inline{Kind: InlineEquation, Text: derefString(element.Equation.Content)}
+			style = element.Equation.TextElementStyle
+		default:
+			inline = Inline{Kind: InlineOther}
+		}
+
+		if style != nil {
+			inline.Bold = derefBool(style.Bold)
+			inline.Italic = derefBool(style.Italic)
+			inline.Strikethrough = derefBool(style.Strikethrough)
+			inline.Underline = derefBool(style.Underline)
+			inline.Code = derefBool(style.InlineCode)
+			if style.Link != nil {
+				inline.Link, _ = url.QueryUnescape(derefString(style.Link.Url))
+			}
+		}
+
+		elements = append(elements, inline)
+	}
+
+	return elements
+}
+
+// larkElements 转换为 SDK 的文本元素，忽略 InlineOther
+func larkElements(elements []Inline) []*larkdocx.TextElement {
+	result := make([]*larkdocx.TextElement, 0, len(elements))
+	for _, inline := range elements {
+		style := &larkdocx.TextElementStyle{
+			Bold:          larkcore.BoolPtr(inline.Bold),
+			Italic:        larkcore.BoolPtr(inline.Italic),
+			Strikethrough: larkcore.BoolPtr(inline.Strikethrough),
+			Underline:     larkcore.BoolPtr(inline.Underline),
+			InlineCode:    larkcore.BoolPtr(inline.Code),
+		}
+		if inline.Link != "" {
+			style.Link = &larkdocx.Link{Url: larkcore.StringPtr(url.QueryEscape(inline.Link))}
+		}
+
+		switch inline.Kind {
+		case InlineText:
+			result = append(result, &larkdocx.TextElement{TextRun: &larkdocx.TextRun{
+				Content:          larkcore.StringPtr(inline.Text),
+				TextElementStyle: style,
+			}})
+		case InlineMentionUser:
+			result = append(result, &larkdocx.TextElement{MentionUser: &larkdocx.MentionUser{
+				UserId:           larkcore.StringPtr(inline.UserId),
+				TextElementStyle: style,
+			}})
+		case InlineMentionDoc:
+			result = append(result, &larkdocx.TextElement{MentionDoc: &larkdocx.MentionDoc{
+				Token:            larkcore.StringPtr(inline.DocToken),
+				ObjType:          larkcore.IntPtr(inline.DocType),
+				Url:              larkcore.StringPtr(url.QueryEscape(inline.DocUrl)),
+				TextElementStyle: style,
+			}})
+		case InlineEquation:
+			result = append(result, &larkdocx.TextElement{Equation: &larkdocx.Equation{
+				Content:          larkcore.StringPtr(inline.Text),
+				TextElementStyle: style,
+			}})
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,496 @@
+package larki
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// DocxDiffResult 增量更新的统计，数量按 Block 计算，包含子 Block
+type DocxDiffResult struct {
+	Unchanged int
+	Updated   int
+	Created   int
+	Deleted   int
+}
+
+// UpdateDocxNodes 将云文档的内容增量更新为 nodes
+// 比较现有 Block 树与期望内容，未变化的 Block 保持不动以保留评论，同类 Block 原地更新文本，其余删除或新建
+// 期望内容中没有的 Block 会被删除；以 ImageSrc 指定的图片会与位置对应的已有图片比较内容，不同时上传替换
+func (c *Client) UpdateDocxNodes(ctx context.Context, docToken string, nodes []*DocxNode, baseDir string, options ...DocxWriteOption) (*DocxDiffResult, error) {
+	tree, err := c.GetDocxTree(ctx, docToken)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &docxDiffer{
+		writer: newDocxWriter(c, docToken, baseDir, options),
+		tree:   tree,
+		result: &DocxDiffResult{},
+	}
+
+	if err = d.diff(ctx, tree.Root.Id, tree.Children(tree.Root), nodes); err != nil {
+		return d.result, err
+	}
+
+	if err = d.flush(ctx); err != nil {
+		return d.result, err
+	}
+
+	return d.result, nil
+}
+
+// UpdateDocxNodes 将云文档的内容增量更新为 nodes
+func UpdateDocxNodes(ctx context.Context, docToken string, nodes []*DocxNode, baseDir string, options ...DocxWriteOption) (*DocxDiffResult, error) {
+	return GlobalClient.UpdateDocxNodes(ctx, docToken, nodes, baseDir, options...)
+}
+
+// UpdateDocxMarkdown 将云文档的内容增量更新为 Markdown，Markdown 以一级标题开头时同时更新文档标题
+func (c *Client) UpdateDocxMarkdown(ctx context.Context, docToken, markdown, baseDir string, options ...DocxWriteOption) (*DocxDiffResult, error) {
+	nodes := ParseMarkdown(markdown)
+	if len(nodes) > 0 {
+		if heading, ok := nodes[0].Block.(*HeadingBlock); ok && heading.Level == 1 {
+			nodes = nodes[1:]
+			if err := c.updateDocxTitle(ctx, docToken, heading.Elements); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return c.UpdateDocxNodes(ctx, docToken, nodes, baseDir, options...)
+}
+
+// UpdateDocxMarkdown 将云文档的内容增量更新为 Markdown
+func UpdateDocxMarkdown(ctx context.Context, docToken, markdown, baseDir string, options ...DocxWriteOption) (*DocxDiffResult, error) {
+	return GlobalClient.UpdateDocxMarkdown(ctx, docToken, markdown, baseDir, options...)
+}
+
+// updateDocxTitle 标题不同时更新文档标题，根 Block 的 ID 与文档 token 相同
+func (c *Client) updateDocxTitle(ctx context.Context, docToken string, title []Inline) error {
+	doc, err := c.GetDocx(ctx, docToken)
+	if err != nil {
+		return err
+	}
+
+	if derefString(doc.Title) == InlinesText(title) {
+		return nil
+	}
+
+	_, err = c.UpdateDocxBlocks(ctx, docToken, UpdateBlockText(docToken, title))
+	return err
+}
+
+type docxDiffer struct {
+	writer  *docxWriter
+	tree    *DocxTree
+	updates []DocxBlockUpdate
+	result  *DocxDiffResult
+}
+
+// docxDiffOp 对齐后的一步操作，oldIndex 或 newIndex 为 -1 表示删除或新建
+type docxDiffOp struct {
+	oldIndex int
+	newIndex int
+	same     bool
+}
+
+// diff 对齐 parentId 下的现有子 Block 与期望节点并执行删除、新建，原地更新在 flush 时批量提交
+func (d *docxDiffer) diff(ctx context.Context, parentId string, current []DocxBlock, desired []*DocxNode) error {
+	ops := d.align(current, desired)
+
+	// pos 为当前操作在服务端子 Block 列表中的位置
+	pos := 0
+	for i := 0; i < len(ops); {
+		op := ops[i]
+		switch {
+		case op.newIndex < 0:
+			end := i
+			deleted := 0
+			for end < len(ops) && ops[end].newIndex < 0 {
+				deleted += d.count(current[ops[end].oldIndex])
+				end++
+			}
+
+			if err := d.writer.client.DeleteDocxBlocks(ctx, d.writer.docToken, parentId, pos, pos+end-i); err != nil {
+				return err
+			}
+
+			d.result.Deleted += deleted
+			i = end
+		case op.oldIndex < 0:
+			end := i
+			var nodes []*DocxNode
+			for end < len(ops) && ops[end].oldIndex < 0 {
+				nodes = append(nodes, desired[ops[end].newIndex])
+				end++
+			}
+
+			if _, err := d.writer.write(ctx, parentId, pos, nodes); err != nil {
+				return err
+			}
+
+			for _, node := range nodes {
+				d.result.Created += countDocxNodes(node)
+			}
+
+			pos += len(nodes)
+			i = end
+		default:
+			old, node := current[op.oldIndex], desired[op.newIndex]
+			if op.same {
+				d.result.Unchanged += d.count(old)
+			} else if err := d.update(ctx, old, node); err != nil {
+				return err
+			}
+
+			pos++
+			i++
+		}
+	}
+
+	return nil
+}
+
+// align 以动态规划对齐两个列表，内容完全相同的 Block 权重高于仅类型相同的 Block
+func (d *docxDiffer) align(current []DocxBlock, desired []*DocxNode) []docxDiffOp {
+	oldSignatures := make([]string, len(current))
+	for i, block := range current {
+		oldSignatures[i] = d.blockSignature(block)
+	}
+
+	newSignatures := make([]string, len(desired))
+	for i, node := range desired {
+		newSignatures[i] = nodeSignature(node)
+	}
+
+	weight := func(i, j int) int {
+		switch {
+		case oldSignatures[i] == newSignatures[j]:
+			return 2
+		case docxPairable(current[i], desired[j].Block):
+			return 1
+		}
+
+		return 0
+	}
+
+	n, m := len(current), len(desired)
+	score := make([][]int, n+1)
+	for i := range score {
+		score[i] = make([]int, m+1)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			best := score[i+1][j]
+			if score[i][j+1] > best {
+				best = score[i][j+1]
+			}
+
+			if w := weight(i, j); w > 0 && w+score[i+1][j+1] > best {
+				best = w + score[i+1][j+1]
+			}
+
+			score[i][j] = best
+		}
+	}
+
+	ops := make([]docxDiffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		if w := weight(i, j); w > 0 && score[i][j] == w+score[i+1][j+1] {
+			ops = append(ops, docxDiffOp{oldIndex: i, newIndex: j, same: w == 2})
+			i++
+			j++
+		} else if score[i][j] == score[i+1][j] {
+			ops = append(ops, docxDiffOp{oldIndex: i, newIndex: -1})
+			i++
+		} else {
+			ops = append(ops, docxDiffOp{oldIndex: -1, newIndex: j})
+			j++
+		}
+	}
+
+	for ; i < n; i++ {
+		ops = append(ops, docxDiffOp{oldIndex: i, newIndex: -1})
+	}
+
+	for ; j < m; j++ {
+		ops = append(ops, docxDiffOp{oldIndex: -1, newIndex: j})
+	}
+
+	return ops
+}
+
+// update 原地更新同类 Block 并递归比较子 Block
+func (d *docxDiffer) update(ctx context.Context, old DocxBlock, node *DocxNode) error {
+	id := old.Base().Id
+	changed := false
+	if elements, ok := docxBlockElements(node.Block); ok {
+		oldElements, _ := docxBlockElements(old)
+		if inlinesSignature(oldElements) != inlinesSignature(elements) {
+			d.updates = append(d.updates, UpdateBlockText(id, elements))
+			changed = true
+		}
+	}
+
+	switch b := node.Block.(type) {
+	case *TodoBlock:
+		if todo, ok := old.(*TodoBlock); ok && todo.Done != b.Done {
+			d.updates = append(d.updates, UpdateTodoDone(id, b.Done))
+			changed = true
+		}
+	case *CodeBlock:
+		if code, ok := old.(*CodeBlock); ok && code.Language != b.Language {
+			d.updates = append(d.updates, UpdateCodeLanguage(id, b.Language))
+			changed = true
+		}
+	case *ImageBlock:
+		image, ok := old.(*ImageBlock)
+		if !ok {
+			break
+		}
+
+		token := b.Token
+		if node.ImageSrc != "" {
+			var err error
+			if token, err = d.imageToken(ctx, image, node.ImageSrc); err != nil {
+				return err
+			}
+		}
+
+		if token != "" && image.Token != token {
+			d.updates = append(d.updates, ReplaceBlockImage(id, token))
+			changed = true
+		}
+	case *TableBlock:
+		table, ok := old.(*TableBlock)
+		if !ok {
+			break
+		}
+
+		before := d.changes()
+		for i, cell := range node.Children {
+			if i >= len(table.Cells) {
+				break
+			}
+
+			cellBlock := d.tree.Block(table.Cells[i])
+			if cellBlock == nil {
+				continue
+			}
+
+			// 单元格至少保留一个段落
+			children := cell.Children
+			if len(children) == 0 {
+				children = []*DocxNode{{Block: &TextBlock{}}}
+			}
+
+			cellBefore := d.changes()
+			if err := d.diff(ctx, cellBlock.Base().Id, d.tree.Children(cellBlock), children); err != nil {
+				return err
+			}
+
+			d.countContainer(cellBefore)
+		}
+
+		d.countContainer(before)
+		return nil
+	}
+
+	if changed {
+		d.result.Updated++
+	} else {
+		d.result.Unchanged++
+	}
+
+	return d.diff(ctx, id, d.tree.Children(old), node.Children)
+}
+
+// imageToken 比较本地图片与已有图片的内容，相同时返回已有素材 token，否则上传本地图片作为该 Block 的新素材
+func (d *docxDiffer) imageToken(ctx context.Context, image *ImageBlock, src string) (string, error) {
+	name, data, err := d.writer.loadImage(ctx, src)
+	if err != nil {
+		return "", err
+	}
+
+	if image.Token != "" {
+		hash, err := fileHash(bytes.NewReader(data))
+		if err != nil {
+			return "", err
+		}
+
+		reader, _, err := d.writer.client.GetDocMedia(ctx, image.Token)
+		if err != nil {
+			return "", err
+		}
+
+		oldHash, err := fileHash(reader)
+		if err != nil {
+			return "", err
+		}
+
+		if oldHash == hash {
+			return image.Token, nil
+		}
+	}
+
+	return d.writer.client.UploadFile(ctx, name, ParentTypeDocxImage, image.Id, len(data), bytes.NewReader(data))
+}
+
+// changes 已发生的变更数
+func (d *docxDiffer) changes() int {
+	return d.result.Updated + d.result.Created + d.result.Deleted
+}
+
+// countContainer 表格等容器自身没有内容，其中有变更时计为更新
+func (d *docxDiffer) countContainer(before int) {
+	if d.changes() > before {
+		d.result.Updated++
+	} else {
+		d.result.Unchanged++
+	}
+}
+
+// flush 提交累积的原地更新
+func (d *docxDiffer) flush(ctx context.Context) error {
+	if len(d.updates) == 0 {
+		return nil
+	}
+
+	_, err := d.writer.client.UpdateDocxBlocks(ctx, d.writer.docToken, d.updates...)
+	return err
+}
+
+// count 统计 Block 及其子 Block 的数量
+func (d *docxDiffer) count(block DocxBlock) int {
+	n := 1
+	for _, child := range d.tree.Children(block) {
+		n += d.count(child)
+	}
+
+	return n
+}
+
+func countDocxNodes(node *DocxNode) int {
+	n := 1
+	for _, child := range node.Children {
+		n += countDocxNodes(child)
+	}
+
+	return n
+}
+
+// docxPairable 判断两个 Block 能否原地更新，Go 类型和 Block 类型都必须相同，表格的行列数必须相同
+func docxPairable(old, block DocxBlock) bool {
+	if reflect.TypeOf(old) != reflect.TypeOf(block) || old.Type() != block.Type() {
+		return false
+	}
+
+	switch b := block.(type) {
+	case *TableBlock:
+		table := old.(*TableBlock)
+		return table.Rows == b.Rows && table.Columns == b.Columns
+	case *UnknownBlock:
+		return false
+	}
+
+	return true
+}
+
+// docxBlockElements 返回可通过 UpdateBlockText 更新的文本
+func docxBlockElements(block DocxBlock) ([]Inline, bool) {
+	switch b := block.(type) {
+	case *TextBlock:
+		return b.Elements, true
+	case *HeadingBlock:
+		return b.Elements, true
+	case *ListBlock:
+		return b.Elements, true
+	case *TodoBlock:
+		return b.Elements, true
+	case *QuoteBlock:
+		return b.Elements, true
+	case *CodeBlock:
+		return b.Elements, true
+	}
+
+	return nil, false
+}
+
+// blockSignature 现有 Block 子树的内容签名
+func (d *docxDiffer) blockSignature(block DocxBlock) string {
+	var builder strings.Builder
+	builder.WriteString(blockContentSignature(block))
+	if table, ok := block.(*TableBlock); ok {
+		for _, id := range table.Cells {
+			builder.WriteString("[")
+			if cell := d.tree.Block(id); cell != nil {
+				builder.WriteString(d.blockSignature(cell))
+			}
+			builder.WriteString("]")
+		}
+
+		return builder.String()
+	}
+
+	for _, child := range d.tree.Children(block) {
+		builder.WriteString("[" + d.blockSignature(child) + "]")
+	}
+
+	return builder.String()
+}
+
+// nodeSignature 期望节点子树的内容签名，与 blockSignature 可直接比较
+func nodeSignature(node *DocxNode) string {
+	var builder strings.Builder
+	builder.WriteString(blockContentSignature(node.Block))
+	for _, child := range node.Children {
+		builder.WriteString("[" + nodeSignature(child) + "]")
+	}
+
+	return builder.String()
+}
+
+// blockContentSignature Block 自身的内容签名
+func blockContentSignature(block DocxBlock) string {
+	signature := fmt.Sprintf("%d", block.Type())
+	switch b := block.(type) {
+	case *TodoBlock:
+		signature += fmt.Sprintf(":%t", b.Done)
+	case *CodeBlock:
+		signature += fmt.Sprintf(":%d", b.Language)
+	case *ImageBlock:
+		signature += ":" + b.Token
+	case *TableBlock:
+		signature += fmt.Sprintf(":%dx%d", b.Rows, b.Columns)
+	case *UnknownBlock:
+		signature += ":" + b.Id
+	}
+
+	if elements, ok := docxBlockElements(block); ok {
+		signature += ":" + inlinesSignature(elements)
+	}
+
+	return signature
+}
+
+// inlinesSignature 合并相同样式的文本后生成签名，避免服务端拆分文本导致误判
+func inlinesSignature(elements []Inline) string {
+	var builder strings.Builder
+	for _, element := range mergeInlines(elements) {
+		text := element.Text
+		if element.Kind == InlineEquation {
+			text = strings.TrimSpace(text)
+		}
+
+		fmt.Fprintf(&builder, "{%d %q %t %t %t %t %t %q %q %q}",
+			element.Kind, text,
+			element.Bold, element.Italic, element.Strikethrough, element.Underline, element.Code,
+			element.Link, element.UserId, element.DocToken)
+	}
+
+	return builder.String()
+}
@@ -0,0 +1,143 @@
+package larki
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+func textBlock(id, text string) *TextBlock {
+	return &TextBlock{BlockBase: BlockBase{Id: id}, Elements: []Inline{PlainText(text)}}
+}
+
+func newTestDiffer(current []DocxBlock) *docxDiffer {
+	root := &PageBlock{BlockBase: BlockBase{Id: "root"}}
+	blocks := map[string]DocxBlock{root.Id: root}
+	for _, block := range current {
+		root.Children = append(root.Children, block.Base().Id)
+		blocks[block.Base().Id] = block
+	}
+
+	return &docxDiffer{tree: &DocxTree{Root: root, blocks: blocks}, result: &DocxDiffResult{}}
+}
+
+// formatOps 将对齐结果格式化为 "=旧:新" 相同、"~旧:新" 原地更新、"-旧" 删除、"+新" 新建
+func formatOps(ops []docxDiffOp) []string {
+	formatted := make([]string, 0, len(ops))
+	for _, op := range ops {
+		switch {
+		case op.newIndex < 0:
+			formatted = append(formatted, fmt.Sprintf("-%d", op.oldIndex))
+		case op.oldIndex < 0:
+			formatted = append(formatted, fmt.Sprintf("+%d", op.newIndex))
+		case op.same:
+			formatted = append(formatted, fmt.Sprintf("=%d:%d", op.oldIndex, op.newIndex))
+		default:
+			formatted = append(formatted, fmt.Sprintf("~%d:%d", op.oldIndex, op.newIndex))
+		}
+	}
+
+	return formatted
+}
+
+func TestDocxDifferAlign(t *testing.T) {
+	tests := []struct {
+		name    string
+		current []DocxBlock
+		desired []*DocxNode
+		want    []string
+	}{
+		{
+			name:    "unchanged",
+			current: []DocxBlock{textBlock("a", "a"), textBlock("b", "b")},
+			desired: []*DocxNode{{Block: textBlock("", "a")}, {Block: textBlock("", "b")}},
+			want:    []string{"=0:0", "=1:1"},
+		},
+		{
+			name:    "insert",
+			current: []DocxBlock{textBlock("a", "a"), textBlock("c", "c")},
+			desired: []*DocxNode{{Block: textBlock("", "a")}, {Block: &DividerBlock{}}, {Block: textBlock("", "c")}},
+			want:    []string{"=0:0", "+1", "=1:2"},
+		},
+		{
+			name:    "delete",
+			current: []DocxBlock{textBlock("a", "a"), &DividerBlock{BlockBase: BlockBase{Id: "d"}}, textBlock("c", "c")},
+			desired: []*DocxNode{{Block: textBlock("", "a")}, {Block: textBlock("", "c")}},
+			want:    []string{"=0:0", "-1", "=2:1"},
+		},
+		{
+			name:    "modify",
+			current: []DocxBlock{textBlock("a", "a"), textBlock("b", "b")},
+			desired: []*DocxNode{{Block: textBlock("", "a")}, {Block: textBlock("", "b2")}},
+			want:    []string{"=0:0", "~1:1"},
+		},
+		{
+			name:    "reorder",
+			current: []DocxBlock{textBlock("a", "a"), &DividerBlock{BlockBase: BlockBase{Id: "d"}}},
+			desired: []*DocxNode{{Block: &DividerBlock{}}, {Block: textBlock("", "a")}},
+			want:    []string{"-0", "=1:0", "+1"},
+		},
+		{
+			name:    "change type",
+			current: []DocxBlock{textBlock("a", "a")},
+			desired: []*DocxNode{{Block: &HeadingBlock{Level: 1, Elements: []Inline{PlainText("a")}}}},
+			want:    []string{"-0", "+0"},
+		},
+		{
+			name: "unknown block with same type",
+			current: []DocxBlock{&UnknownBlock{
+				BlockBase: BlockBase{Id: "u"},
+				Raw:       &larkdocx.Block{BlockType: larkdocxInt(int(DocxBlockTodo))},
+			}},
+			desired: []*DocxNode{{Block: &TodoBlock{Elements: []Inline{PlainText("a")}}}},
+			want:    []string{"-0", "+0"},
+		},
+		{
+			name:    "empty",
+			current: nil,
+			desired: []*DocxNode{{Block: textBlock("", "a")}},
+			want:    []string{"+0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := newTestDiffer(tt.current)
+			got := formatOps(d.align(tt.current, tt.desired))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("align() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDocxPairable(t *testing.T) {
+	unknownTodo := &UnknownBlock{Raw: &larkdocx.Block{BlockType: larkdocxInt(int(DocxBlockTodo))}}
+	tests := []struct {
+		name  string
+		old   DocxBlock
+		block DocxBlock
+		want  bool
+	}{
+		{"same type", textBlock("a", "a"), textBlock("", "b"), true},
+		{"different type", textBlock("a", "a"), &DividerBlock{}, false},
+		{"unknown with same raw type", unknownTodo, &TodoBlock{}, false},
+		{"known against unknown", &TodoBlock{}, unknownTodo, false},
+		{"table same size", &TableBlock{Rows: 2, Columns: 2}, &TableBlock{Rows: 2, Columns: 2}, true},
+		{"table different size", &TableBlock{Rows: 2, Columns: 2}, &TableBlock{Rows: 3, Columns: 2}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := docxPairable(tt.old, tt.block); got != tt.want {
+				t.Errorf("docxPairable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func larkdocxInt(v int) *int {
+	return &v
+}
@@ -0,0 +1,455 @@
+package larki
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+)
+
+const (
+	docFileDownloadPath         = "/open-apis/drive/v1/files/:file_token/download"
+	docMediaDownloadPath        = "/open-apis/drive/v1/medias/:file_token/download"
+	messageResourceDownloadPath = "/open-apis/im/v1/messages/:message_id/resources/:file_key"
+
+	// downloadChunkSize 单次 Range 请求的字节数
+	downloadChunkSize = 8 << 20
+)
+
+// ErrDownloadChanged 下载过程中远端资源发生了变化
+var ErrDownloadChanged = errors.New("larki: remote file changed during download")
+
+// Download 流式下载的响应，使用完毕后需要 Close
+type Download struct {
+	io.ReadCloser
+	Name        string
+	ContentType string
+	// ContentLength 本次响应的字节数，未知时为 -1
+	ContentLength int64
+	// Offset 本次响应在资源中的起始偏移
+	Offset int64
+	// TotalSize 资源的总大小，未知时为 -1
+	TotalSize int64
+	// Validator 资源的 ETag 或 Last-Modified，可配合 WithIfRange 续传
+	Validator string
+}
+
+// DownloadOption 下载配置
+type DownloadOption func(*downloadOptions)
+
+type downloadOptions struct {
+	offset  int64
+	length  int64
+	ifRange string
+}
+
+// WithRange 只下载从 offset 开始的 length 个字节，length <= 0 时下载到末尾
+func WithRange(offset, length int64) DownloadOption {
+	return func(o *downloadOptions) {
+		o.offset = offset
+		o.length = length
+	}
+}
+
+// WithIfRange 仅当远端资源的 ETag 或 Last-Modified 仍为 validator 时按 Range 下载，否则返回完整资源
+func WithIfRange(validator string) DownloadOption {
+	return func(o *downloadOptions) {
+		o.ifRange = validator
+	}
+}
+
+// DownloadSource 可流式下载的资源
+type DownloadSource func(ctx context.Context, options ...DownloadOption) (*Download, error)
+
+// DocFileSource 云空间文件
+func (c *Client) DocFileSource(fileToken string) DownloadSource {
+	return func(ctx context.Context, options ...DownloadOption) (*Download, error) {
+		return c.DownloadDocFile(ctx, fileToken, options...)
+	}
+}
+
+// DocMediaSource 云文档素材
+func (c *Client) DocMediaSource(fileToken string) DownloadSource {
+	return func(ctx context.Context, options ...DownloadOption) (*Download, error) {
+		return c.DownloadDocMedia(ctx, fileToken, options...)
+	}
+}
+
+// MessageResourceSource 消息中的图片或文件，resourceType 为 image 或 file
+func (c *Client) MessageResourceSource(messageId, fileKey, resourceType string) DownloadSource {
+	return func(ctx context.Context, options ...DownloadOption) (*Download, error) {
+		return c.DownloadMessageResource(ctx, messageId, fileKey, resourceType, options...)
+	}
+}
+
+// DownloadDocFile 流式下载云空间文件
+func (c *Client) DownloadDocFile(ctx context.Context, fileToken string, options ...DownloadOption) (*Download, error) {
+	return c.download(ctx, &larkcore.ApiReq{
+		HttpMethod: http.MethodGet,
+		ApiPath:    docFileDownloadPath,
+		PathParams: larkcore.PathParams{"file_token": fileToken},
+	}, "DownloadDocFile", options...)
+}
+
+// DownloadDocFile 流式下载云空间文件
+func DownloadDocFile(ctx context.Context, fileToken string, options ...DownloadOption) (*Download, error) {
+	return GlobalClient.DownloadDocFile(ctx, fileToken, options...)
+}
+
+// DownloadDocMedia 流式下载云文档素材
+func (c *Client) DownloadDocMedia(ctx context.Context, fileToken string, options ...DownloadOption) (*Download, error) {
+	return c.download(ctx, &larkcore.ApiReq{
+		HttpMethod: http.MethodGet,
+		ApiPath:    docMediaDownloadPath,
+		PathParams: larkcore.PathParams{"file_token": fileToken},
+	}, "DownloadDocMedia", options...)
+}
+
+// DownloadDocMedia 流式下载云文档素材
+func DownloadDocMedia(ctx context.Context, fileToken string, options ...DownloadOption) (*Download, error) {
+	return GlobalClient.DownloadDocMedia(ctx, fileToken, options...)
+}
+
+// DownloadMessageResource 流式下载消息中的图片或文件
+func (c *Client) DownloadMessageResource(ctx context.Context, messageId, fileKey, resourceType string, options ...DownloadOption) (*Download, error) {
+	return c.download(ctx, &larkcore.ApiReq{
+		HttpMethod:  http.MethodGet,
+		ApiPath:     messageResourceDownloadPath,
+		PathParams:  larkcore.PathParams{"message_id": messageId, "file_key": fileKey},
+		QueryParams: larkcore.QueryParams{"type": []string{resourceType}},
+	}, "DownloadMessageResource", options...)
+}
+
+// DownloadMessageResource 流式下载消息中的图片或文件
+func DownloadMessageResource(ctx context.Context, messageId, fileKey, resourceType string, options ...DownloadOption) (*Download, error) {
+	return GlobalClient.DownloadMessageResource(ctx, messageId, fileKey, resourceType, options...)
+}
+
+// DownloadToFile 下载到本地文件，先写入 path.part 再原子地重命名
+// 已存在的 path.part 会被视为上次中断的下载，远端资源未变化时通过 Range 请求继续，否则重新下载
+func (c *Client) DownloadToFile(ctx context.Context, source DownloadSource, path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	partPath := path + ".part"
+	validatorPath := partPath + ".validator"
+	file, err := os.OpenFile(partPath, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	offset := info.Size()
+	var options []DownloadOption
+	if offset > 0 {
+		// 没有记录远端版本时无法确认 part 文件仍然有效
+		validator, _ := os.ReadFile(validatorPath)
+		if len(validator) > 0 {
+			options = append(options, WithRange(offset, 0), WithIfRange(string(validator)))
+		} else {
+			offset = 0
+		}
+	}
+
+	download, err := source(ctx, options...)
+	if err != nil {
+		// 416 说明 part 文件已经是完整的
+		var larkErr *LarkError
+		if offset == 0 || !errors.As(err, &larkErr) || larkErr.HTTPStatus != http.StatusRequestedRangeNotSatisfiable {
+			return err
+		}
+	} else {
+		defer download.Close()
+
+		// 服务端忽略 Range 或远端资源已变化时从头写入
+		if download.Offset != offset {
+			offset = 0
+		}
+
+		if download.Validator != "" {
+			err = os.WriteFile(validatorPath, []byte(download.Validator), 0o644)
+		} else {
+			err = os.Remove(validatorPath)
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		if err = file.Truncate(offset); err != nil {
+			return err
+		}
+
+		if _, err = file.Seek(offset, io.SeekStart); err != nil {
+			return err
+		}
+
+		written, err := io.Copy(file, download)
+		if err != nil {
+			return err
+		}
+
+		if download.ContentLength >= 0 && written != download.ContentLength {
+			return io.ErrUnexpectedEOF
+		}
+	}
+
+	if err = file.Sync(); err != nil {
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	if err = os.Rename(partPath, path); err != nil {
+		return err
+	}
+
+	if err = os.Remove(validatorPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+// DownloadToFile 下载到本地文件
+func DownloadToFile(ctx context.Context, source DownloadSource, path string) error {
+	return GlobalClient.DownloadToFile(ctx, source, path)
+}
+
+// download 发起流式下载请求，按 downloadChunkSize 分段发起 Range 请求，读取时再拉取后续分段
+func (c *Client) download(ctx context.Context, req *larkcore.ApiReq, op string, options ...DownloadOption) (*Download, error) {
+	opts := &downloadOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+
+	end := opts.offset + downloadChunkSize - 1
+	if opts.length > 0 && opts.offset+opts.length-1 < end {
+		end = opts.offset + opts.length - 1
+	}
+
+	resp, err := c.fetchRange(ctx, req, op, opts.offset, end, opts.ifRange)
+	if err != nil {
+		return nil, err
+	}
+
+	download := &Download{
+		Name:        larkcore.FileNameByHeader(resp.Header),
+		ContentType: resp.Header.Get("Content-Type"),
+		Validator:   responseValidator(resp.Header),
+	}
+
+	if download.Name == "" {
+		if _, params, err := mime.ParseMediaType(resp.Header.Get("Content-Disposition")); err == nil {
+			download.Name = params["filename"]
+		}
+	}
+
+	// 服务端忽略 Range 或 If-Range 不匹配时返回完整资源
+	if resp.StatusCode != http.StatusPartialContent {
+		download.ReadCloser = io.NopCloser(bytes.NewReader(resp.RawBody))
+		download.ContentLength = int64(len(resp.RawBody))
+		download.TotalSize = download.ContentLength
+		return download, nil
+	}
+
+	download.Offset, download.TotalSize = parseContentRange(resp.Header.Get("Content-Range"))
+	reader := &rangeReader{
+		ctx:     ctx,
+		c:       c,
+		req:     req,
+		op:      op,
+		buf:     resp.RawBody,
+		next:    download.Offset + int64(len(resp.RawBody)),
+		end:     download.TotalSize,
+		ifRange: download.Validator,
+	}
+
+	if opts.length > 0 && (reader.end < 0 || download.Offset+opts.length < reader.end) {
+		reader.end = download.Offset + opts.length
+	}
+
+	// 总大小未知且本段未取满时视为已到末尾
+	if reader.end < 0 && int64(len(resp.RawBody)) < end-opts.offset+1 {
+		reader.end = reader.next
+	}
+
+	download.ContentLength = -1
+	if reader.end >= 0 {
+		download.ContentLength = reader.end - download.Offset
+	}
+
+	download.ReadCloser = reader
+	return download, nil
+}
+
+// fetchRange 经由 SDK 请求资源的 [start, end] 区间，ifRange 非空时附带 If-Range
+func (c *Client) fetchRange(ctx context.Context, req *larkcore.ApiReq, op string, start, end int64, ifRange string) (*larkcore.ApiResp, error) {
+	header := http.Header{}
+	header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end))
+	if ifRange != "" {
+		header.Set("If-Range", ifRange)
+	}
+
+	options := []larkcore.RequestOptionFunc{larkcore.WithFileDownload(), larkcore.WithHeaders(header)}
+	if tenantKey := tenantKeyFromContext(ctx); tenantKey != "" {
+		options = append(options, larkcore.WithTenantKey(tenantKey))
+	}
+
+	apiReq := *req
+	apiReq.SupportedAccessTokenTypes = []larkcore.AccessTokenType{larkcore.AccessTokenTypeTenant}
+
+	var resp *larkcore.ApiResp
+	err := c.retry(ctx, retrySafe, func() error {
+		var err error
+		resp, err = c.Do(ctx, &apiReq, options...)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+			return newApiRespError(resp, op)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+// rangeReader 顺序读取资源，当前分段读完后再请求下一段
+type rangeReader struct {
+	ctx     context.Context
+	c       *Client
+	req     *larkcore.ApiReq
+	op      string
+	buf     []byte
+	next    int64
+	end     int64
+	ifRange string
+	closed  bool
+}
+
+func (r *rangeReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.closed {
+			return 0, os.ErrClosed
+		}
+
+		if r.end >= 0 && r.next >= r.end {
+			return 0, io.EOF
+		}
+
+		if err := r.fetch(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// fetch 拉取下一段，远端资源变化时返回 ErrDownloadChanged
+func (r *rangeReader) fetch() error {
+	end := r.next + downloadChunkSize - 1
+	if r.end >= 0 && end >= r.end {
+		end = r.end - 1
+	}
+
+	resp, err := r.c.fetchRange(r.ctx, r.req, r.op, r.next, end, r.ifRange)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusPartialContent {
+		return ErrDownloadChanged
+	}
+
+	if start, _ := parseContentRange(resp.Header.Get("Content-Range")); start != r.next {
+		return ErrDownloadChanged
+	}
+
+	if len(resp.RawBody) == 0 {
+		if r.end < 0 {
+			r.end = r.next
+			return nil
+		}
+		return io.ErrUnexpectedEOF
+	}
+
+	if r.end < 0 && int64(len(resp.RawBody)) < end-r.next+1 {
+		r.end = r.next + int64(len(resp.RawBody))
+	}
+
+	r.buf = resp.RawBody
+	r.next += int64(len(resp.RawBody))
+	return nil
+}
+
+func (r *rangeReader) Close() error {
+	r.closed = true
+	r.buf = nil
+	return nil
+}
+
+// responseValidator 返回响应的 ETag，没有时返回 Last-Modified，弱 ETag 不能用于 If-Range
+func responseValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return header.Get("Last-Modified")
+}
+
+// parseContentRange 解析 "bytes start-end/total"，total 未知时返回 -1
+func parseContentRange(contentRange string) (int64, int64) {
+	contentRange = strings.TrimPrefix(contentRange, "bytes ")
+	rangePart, totalPart, ok := strings.Cut(contentRange, "/")
+	if !ok {
+		return 0, -1
+	}
+
+	startPart, _, _ := strings.Cut(rangePart, "-")
+	start, err := strconv.ParseInt(startPart, 10, 64)
+	if err != nil {
+		start = 0
+	}
+
+	total, err := strconv.ParseInt(totalPart, 10, 64)
+	if err != nil {
+		total = -1
+	}
+
+	return start, total
+}
+
+// newApiRespError 由非 2xx 的响应构造飞书服务端报错，响应中没有业务码时只记录 HTTP 状态码
+func newApiRespError(resp *larkcore.ApiResp, op string) error {
+	var codeErr larkcore.CodeError
+	if err := sonic.Unmarshal(resp.RawBody, &codeErr); err != nil || codeErr.Code == 0 {
+		return newLarkRespError(resp, 0, http.StatusText(resp.StatusCode), op)
+	}
+
+	return newLarkRespError(resp, codeErr.Code, codeErr.Msg, op)
+}
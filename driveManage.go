@@ -0,0 +1,326 @@
+package larki
+
+import (
+	"context"
+	"fmt"
+
+	larkbitable "github.com/larksuite/oapi-sdk-go/v3/service/bitable/v1"
+	larkdrive "github.com/larksuite/oapi-sdk-go/v3/service/drive/v1"
+	larksheets "github.com/larksuite/oapi-sdk-go/v3/service/sheets/v3"
+)
+
+// 云空间异步任务状态
+const (
+	DriveTaskSuccess = "success"
+	DriveTaskFail    = "fail"
+	DriveTaskProcess = "process"
+)
+
+// DriveBatchMetaMaxSize 一次查询元数据的文件数量上限
+const DriveBatchMetaMaxSize = 200
+
+// DriveDoc 云空间中的文件或文档
+type DriveDoc struct {
+	Token string
+	Type  string
+}
+
+// CopyDriveFile 复制文件到 folderToken 下并命名为 name，文件夹不支持复制
+func (c *Client) CopyDriveFile(ctx context.Context, fileToken, fileType, name, folderToken string) (*larkdrive.File, error) {
+	req := larkdrive.NewCopyFileReqBuilder().
+		FileToken(fileToken).
+		Body(larkdrive.NewCopyFileReqBodyBuilder().
+			Name(name).
+			Type(fileType).
+			FolderToken(folderToken).
+			Build()).
+		Build()
+
+	var resp *larkdrive.CopyFileResp
+	err := c.retry(ctx, retryRateLimited, func() (err error) {
+		resp, err = c.Drive.File.Copy(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "CopyDriveFile")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.File, nil
+}
+
+// CopyDriveFile 复制文件到 folderToken 下
+func CopyDriveFile(ctx context.Context, fileToken, fileType, name, folderToken string) (*larkdrive.File, error) {
+	return GlobalClient.CopyDriveFile(ctx, fileToken, fileType, name, folderToken)
+}
+
+// MoveDriveFile 移动文件或文件夹到 folderToken 下，移动文件夹时等待异步任务完成
+func (c *Client) MoveDriveFile(ctx context.Context, fileToken, fileType, folderToken string) error {
+	req := larkdrive.NewMoveFileReqBuilder().
+		FileToken(fileToken).
+		Body(larkdrive.NewMoveFileReqBodyBuilder().
+			Type(fileType).
+			FolderToken(folderToken).
+			Build()).
+		Build()
+
+	var resp *larkdrive.MoveFileResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Drive.File.Move(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "MoveDriveFile")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.WaitDriveTask(ctx, derefString(resp.Data.TaskId))
+}
+
+// MoveDriveFile 移动文件或文件夹到 folderToken 下
+func MoveDriveFile(ctx context.Context, fileToken, fileType, folderToken string) error {
+	return GlobalClient.MoveDriveFile(ctx, fileToken, fileType, folderToken)
+}
+
+// DeleteDriveFile 删除云空间中的文件或文件夹，fileType 为 file、folder、docx 等
+// 删除文件夹时等待异步任务完成
+func (c *Client) DeleteDriveFile(ctx context.Context, fileToken, fileType string) error {
+	req := larkdrive.NewDeleteFileReqBuilder().
+		FileToken(fileToken).
+		Type(fileType).
+		Build()
+
+	var resp *larkdrive.DeleteFileResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Drive.File.Delete(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "DeleteDriveFile")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.WaitDriveTask(ctx, derefString(resp.Data.TaskId))
+}
+
+// DeleteDriveFile 删除云空间中的文件或文件夹
+func DeleteDriveFile(ctx context.Context, fileToken, fileType string) error {
+	return GlobalClient.DeleteDriveFile(ctx, fileToken, fileType)
+}
+
+// RenameDriveFile 重命名云文档，fileType 为 docx、sheet 或 bitable，分别修改文档标题、表格标题和多维表格名称
+// 开放平台没有修改普通文件、文件夹和旧版文档名称的接口，这些类型返回错误
+func (c *Client) RenameDriveFile(ctx context.Context, fileToken, fileType, name string) error {
+	switch fileType {
+	case "docx":
+		return c.updateDocxTitle(ctx, fileToken, []Inline{PlainText(name)})
+	case "sheet":
+		req := larksheets.NewPatchSpreadsheetReqBuilder().
+			SpreadsheetToken(fileToken).
+			UpdateSpreadsheetProperties(larksheets.NewUpdateSpreadsheetPropertiesBuilder().
+				Title(name).
+				Build()).
+			Build()
+
+		return c.retry(ctx, retrySafe, func() error {
+			resp, err := c.Sheets.Spreadsheet.Patch(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "RenameDriveFile")
+			}
+
+			return nil
+		})
+	case "bitable":
+		req := larkbitable.NewUpdateAppReqBuilder().
+			AppToken(fileToken).
+			Body(larkbitable.NewUpdateAppReqBodyBuilder().
+				Name(name).
+				Build()).
+			Build()
+
+		return c.retry(ctx, retrySafe, func() error {
+			resp, err := c.Bitable.App.Update(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "RenameDriveFile")
+			}
+
+			return nil
+		})
+	}
+
+	return fmt.Errorf("larki: RenameDriveFile does not support file type %q", fileType)
+}
+
+// RenameDriveFile 重命名云文档
+func RenameDriveFile(ctx context.Context, fileToken, fileType, name string) error {
+	return GlobalClient.RenameDriveFile(ctx, fileToken, fileType, name)
+}
+
+// GetDriveTaskStatus 查询移动、删除文件夹等异步任务的状态
+func (c *Client) GetDriveTaskStatus(ctx context.Context, taskId string) (string, error) {
+	req := larkdrive.NewTaskCheckFileReqBuilder().TaskId(taskId).Build()
+
+	var resp *larkdrive.TaskCheckFileResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Drive.File.TaskCheck(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetDriveTaskStatus")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return derefString(resp.Data.Status), nil
+}
+
+// GetDriveTaskStatus 查询云空间异步任务的状态
+func GetDriveTaskStatus(ctx context.Context, taskId string) (string, error) {
+	return GlobalClient.GetDriveTaskStatus(ctx, taskId)
+}
+
+// WaitDriveTask 轮询云空间异步任务直到完成，taskId 为空时直接返回，任务失败时返回 *TaskError
+func (c *Client) WaitDriveTask(ctx context.Context, taskId string, options ...PollOption) error {
+	if taskId == "" {
+		return nil
+	}
+
+	_, err := PollTask(ctx, func(ctx context.Context) (string, bool, error) {
+		status, err := c.GetDriveTaskStatus(ctx, taskId)
+		if err != nil {
+			return "", false, err
+		}
+
+		switch status {
+		case DriveTaskSuccess:
+			return status, true, nil
+		case DriveTaskFail:
+			return status, false, &TaskError{Op: "DriveTask", TaskId: taskId, Status: -1, Msg: status}
+		}
+
+		return status, false, nil
+	}, options...)
+
+	return err
+}
+
+// WaitDriveTask 轮询云空间异步任务直到完成
+func WaitDriveTask(ctx context.Context, taskId string, options ...PollOption) error {
+	return GlobalClient.WaitDriveTask(ctx, taskId, options...)
+}
+
+// BatchGetDriveMeta 批量获取文件元数据，超过 DriveBatchMetaMaxSize 个时分批查询
+// 无法获取元数据的文件在第二个返回值中
+func (c *Client) BatchGetDriveMeta(ctx context.Context, docs []DriveDoc) ([]*larkdrive.Meta, []*larkdrive.MetaFailed, error) {
+	var metas []*larkdrive.Meta
+	var failed []*larkdrive.MetaFailed
+
+	for start := 0; start < len(docs); start += DriveBatchMetaMaxSize {
+		batch := docs[start:minInt(start+DriveBatchMetaMaxSize, len(docs))]
+		requestDocs := make([]*larkdrive.RequestDoc, 0, len(batch))
+		for _, doc := range batch {
+			requestDocs = append(requestDocs, larkdrive.NewRequestDocBuilder().DocToken(doc.Token).DocType(doc.Type).Build())
+		}
+
+		req := larkdrive.NewBatchQueryMetaReqBuilder().
+			MetaRequest(larkdrive.NewMetaRequestBuilder().
+				RequestDocs(requestDocs).
+				WithUrl(true).
+				Build()).
+			Build()
+
+		var resp *larkdrive.BatchQueryMetaResp
+		err := c.retry(ctx, retrySafe, func() (err error) {
+			resp, err = c.Drive.Meta.BatchQuery(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "BatchGetDriveMeta")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+
+		metas = append(metas, resp.Data.Metas...)
+		failed = append(failed, resp.Data.FailedList...)
+	}
+
+	return metas, failed, nil
+}
+
+// BatchGetDriveMeta 批量获取文件元数据
+func BatchGetDriveMeta(ctx context.Context, docs []DriveDoc) ([]*larkdrive.Meta, []*larkdrive.MetaFailed, error) {
+	return GlobalClient.BatchGetDriveMeta(ctx, docs)
+}
+
+// GetDriveFileStatistics 获取文件的访问人数、访问次数和点赞数
+func (c *Client) GetDriveFileStatistics(ctx context.Context, fileToken, fileType string) (*larkdrive.FileStatistics, error) {
+	req := larkdrive.NewGetFileStatisticsReqBuilder().
+		FileToken(fileToken).
+		FileType(fileType).
+		Build()
+
+	var resp *larkdrive.GetFileStatisticsResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Drive.FileStatistics.Get(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetDriveFileStatistics")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.Statistics, nil
+}
+
+// GetDriveFileStatistics 获取文件的统计信息
+func GetDriveFileStatistics(ctx context.Context, fileToken, fileType string) (*larkdrive.FileStatistics, error) {
+	return GlobalClient.GetDriveFileStatistics(ctx, fileToken, fileType)
+}
@@ -0,0 +1,303 @@
+package larki
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdrive "github.com/larksuite/oapi-sdk-go/v3/service/drive/v1"
+)
+
+const driveRootFolderUrl = "https://open.feishu.cn/open-apis/drive/explorer/v2/root_folder/meta"
+
+// ErrSkipFolder 在 WalkDriveFunc 中返回时不再进入该文件夹
+var ErrSkipFolder = errors.New("larki: skip this folder")
+
+// WalkDriveFunc 遍历时对每个文件调用，path 为相对起始文件夹的路径，以 / 开头
+type WalkDriveFunc func(path string, file *larkdrive.File) error
+
+// WalkOption 遍历配置
+type WalkOption func(*walkOptions)
+
+type walkOptions struct {
+	maxDepth    int
+	concurrency int
+}
+
+// WithWalkDepth 最多遍历 depth 层，1 表示只遍历起始文件夹的直接子项，0 表示不限制
+func WithWalkDepth(depth int) WalkOption {
+	return func(o *walkOptions) {
+		o.maxDepth = depth
+	}
+}
+
+// WithWalkConcurrency 同时列出的文件夹数量，默认为 1
+func WithWalkConcurrency(concurrency int) WalkOption {
+	return func(o *walkOptions) {
+		if concurrency > 0 {
+			o.concurrency = concurrency
+		}
+	}
+}
+
+type driveWalker struct {
+	client *Client
+	fn     WalkDriveFunc
+	opts   *walkOptions
+	sem    chan struct{}
+	cancel context.CancelFunc
+
+	wg   sync.WaitGroup
+	mu   sync.Mutex
+	once sync.Once
+	err  error
+}
+
+// WalkDrive 递归遍历云空间文件夹，文件夹先于其子项回调
+// 并发遍历时同一文件夹内的顺序不变，但不同文件夹之间的回调顺序不确定；fn 不会被并发调用
+// fn 返回 ErrSkipFolder 时跳过该文件夹，返回其他错误时停止遍历并返回该错误
+func (c *Client) WalkDrive(ctx context.Context, folderToken string, fn WalkDriveFunc, options ...WalkOption) error {
+	opts := &walkOptions{concurrency: 1}
+	for _, option := range options {
+		option(opts)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := &driveWalker{
+		client: c,
+		fn:     fn,
+		opts:   opts,
+		sem:    make(chan struct{}, opts.concurrency),
+		cancel: cancel,
+	}
+
+	w.walk(ctx, folderToken, "/", 1)
+	w.wg.Wait()
+	return w.err
+}
+
+// WalkDrive 递归遍历云空间文件夹
+func WalkDrive(ctx context.Context, folderToken string, fn WalkDriveFunc, options ...WalkOption) error {
+	return GlobalClient.WalkDrive(ctx, folderToken, fn, options...)
+}
+
+func (w *driveWalker) fail(err error) {
+	w.once.Do(func() {
+		w.err = err
+		w.cancel()
+	})
+}
+
+func (w *driveWalker) walk(ctx context.Context, folderToken, dir string, depth int) {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		select {
+		case w.sem <- struct{}{}:
+		case <-ctx.Done():
+			w.fail(ctx.Err())
+			return
+		}
+
+		files, err := w.client.ListDriveFolder(ctx, folderToken)
+		<-w.sem
+		if err != nil {
+			w.fail(err)
+			return
+		}
+
+		for _, file := range files {
+			p := path.Join(dir, derefString(file.Name))
+
+			w.mu.Lock()
+			err = ctx.Err()
+			if err == nil {
+				err = w.fn(p, file)
+			}
+			w.mu.Unlock()
+
+			if errors.Is(err, ErrSkipFolder) {
+				continue
+			}
+
+			if err != nil {
+				w.fail(err)
+				return
+			}
+
+			if derefString(file.Type) == DriveTypeFolder && (w.opts.maxDepth <= 0 || depth < w.opts.maxDepth) {
+				w.walk(ctx, derefString(file.Token), p, depth+1)
+			}
+		}
+	}()
+}
+
+type rootFolderResp struct {
+	Code int    `json:"code"`
+	Msg  string `json:"msg"`
+	Data struct {
+		Token string `json:"token"`
+	} `json:"data"`
+}
+
+// GetRootFolder 获取应用云空间根文件夹的 token
+func (c *Client) GetRootFolder(ctx context.Context) (string, error) {
+	var token string
+	err := c.retry(ctx, retrySafe, func() error {
+		resp, err := c.Get(ctx, driveRootFolderUrl, nil, larkcore.AccessTokenTypeTenant)
+		if err != nil {
+			return err
+		}
+
+		var data rootFolderResp
+		if err = sonic.Unmarshal(resp.RawBody, &data); err != nil {
+			return err
+		}
+
+		if data.Code != 0 {
+			return newLarkRespError(resp, data.Code, data.Msg, "GetRootFolder")
+		}
+
+		token = data.Data.Token
+		return nil
+	})
+
+	return token, err
+}
+
+// GetRootFolder 获取应用云空间根文件夹的 token
+func GetRootFolder(ctx context.Context) (string, error) {
+	return GlobalClient.GetRootFolder(ctx)
+}
+
+// ResolvePath 按路径查找云空间根文件夹下的文件，例如 /Reports/2026/Q3.xlsx
+// 同名文件存在多个时返回第一个
+func (c *Client) ResolvePath(ctx context.Context, p string) (*larkdrive.File, error) {
+	root, err := c.GetRootFolder(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ResolvePathFrom(ctx, root, p)
+}
+
+// ResolvePath 按路径查找云空间根文件夹下的文件
+func ResolvePath(ctx context.Context, p string) (*larkdrive.File, error) {
+	return GlobalClient.ResolvePath(ctx, p)
+}
+
+// ResolvePathFrom 按相对 folderToken 的路径查找文件
+func (c *Client) ResolvePathFrom(ctx context.Context, folderToken, p string) (*larkdrive.File, error) {
+	folderType := DriveTypeFolder
+	file := &larkdrive.File{Token: &folderToken, Type: &folderType}
+
+	for _, name := range splitDrivePath(p) {
+		if derefString(file.Type) != DriveTypeFolder {
+			return nil, newNotFoundError(fmt.Sprintf("%s is not a folder", derefString(file.Name)), "ResolvePath")
+		}
+
+		child, err := c.findDriveChild(ctx, derefString(file.Token), name)
+		if err != nil {
+			return nil, err
+		}
+
+		if child == nil {
+			return nil, newNotFoundError(fmt.Sprintf("path not found: %s", p), "ResolvePath")
+		}
+
+		file = child
+	}
+
+	return file, nil
+}
+
+// ResolvePathFrom 按相对 folderToken 的路径查找文件
+func ResolvePathFrom(ctx context.Context, folderToken, p string) (*larkdrive.File, error) {
+	return GlobalClient.ResolvePathFrom(ctx, folderToken, p)
+}
+
+// MkdirAll 在云空间根文件夹下按路径逐级创建文件夹，已存在的文件夹直接复用，返回最后一级的 token
+func (c *Client) MkdirAll(ctx context.Context, p string) (string, error) {
+	root, err := c.GetRootFolder(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	return c.MkdirAllFrom(ctx, root, p)
+}
+
+// MkdirAll 在云空间根文件夹下按路径逐级创建文件夹
+func MkdirAll(ctx context.Context, p string) (string, error) {
+	return GlobalClient.MkdirAll(ctx, p)
+}
+
+// MkdirAllFrom 在 folderToken 下按路径逐级创建文件夹
+func (c *Client) MkdirAllFrom(ctx context.Context, folderToken, p string) (string, error) {
+	token := folderToken
+	for _, name := range splitDrivePath(p) {
+		child, err := c.findDriveChild(ctx, token, name)
+		if err != nil {
+			return "", err
+		}
+
+		if child == nil {
+			if token, err = c.CreateDriveFolder(ctx, name, token); err != nil {
+				return "", err
+			}
+
+			continue
+		}
+
+		if derefString(child.Type) != DriveTypeFolder {
+			return "", fmt.Errorf("larki: %s exists and is not a folder", name)
+		}
+
+		token = derefString(child.Token)
+	}
+
+	return token, nil
+}
+
+// MkdirAllFrom 在 folderToken 下按路径逐级创建文件夹
+func MkdirAllFrom(ctx context.Context, folderToken, p string) (string, error) {
+	return GlobalClient.MkdirAllFrom(ctx, folderToken, p)
+}
+
+// findDriveChild 查找文件夹下指定名称的文件，不存在时返回 nil
+func (c *Client) findDriveChild(ctx context.Context, folderToken, name string) (*larkdrive.File, error) {
+	pager := c.IterDriveFolder(folderToken)
+	for {
+		file, err := pager.Next(ctx)
+		if errors.Is(err, ErrIteratorDone) {
+			return nil, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if derefString(file.Name) == name {
+			return file, nil
+		}
+	}
+}
+
+// splitDrivePath 拆分以 / 分隔的路径，忽略空段
+func splitDrivePath(p string) []string {
+	var names []string
+	for _, name := range strings.Split(p, "/") {
+		if name != "" && name != "." {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
@@ -0,0 +1,131 @@
+package larki
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+)
+
+// CodeNotFound 本地查询不到目标时使用的错误码
+const CodeNotFound = -1
+
+// LarkError 飞书服务端报错
+type LarkError struct {
+	Code       int
+	Msg        string
+	Op         string
+	LogID      string
+	HTTPStatus int
+	// RetryAfter 被限流时距离限流重置的时间
+	RetryAfter time.Duration
+}
+
+func (e *LarkError) Error() string {
+	if e.LogID != "" {
+		return fmt.Sprintf(larkErrFormat+", log_id: %s", e.Op, e.Code, e.Msg, e.LogID)
+	}
+
+	return fmt.Sprintf(larkErrFormat, e.Op, e.Code, e.Msg)
+}
+
+var (
+	rateLimitedCodes = map[int]struct{}{
+		99991400: {}, // 应用频率限制
+		230020:   {}, // 消息发送频率限制
+		1061045:  {}, // 云空间频率限制
+		1254290:  {}, // 多维表格频率限制
+		131008:   {}, // 知识库频率限制
+	}
+
+	permissionDeniedCodes = map[int]struct{}{
+		99991672: {}, // 应用未开通权限
+		99991679: {}, // 用户未授权
+		230027:   {}, // 无消息权限
+		1061004:  {}, // 云空间无权限
+		1254302:  {}, // 多维表格无权限
+		131006:   {}, // 知识库无权限
+		1770032:  {}, // 文档无权限
+	}
+
+	notFoundCodes = map[int]struct{}{
+		CodeNotFound: {},
+		1061003:      {}, // 云空间文件不存在
+		1061007:      {}, // 云空间文件已删除
+		1254040:      {}, // 多维表格不存在
+		1254041:      {}, // 数据表不存在
+		1254043:      {}, // 记录不存在
+		131005:       {}, // 知识库节点不存在
+		1770002:      {}, // 文档不存在
+	}
+
+	tokenInvalidCodes = map[int]struct{}{
+		99991661: {}, // 缺少 access token
+		99991663: {}, // tenant access token 无效
+		99991664: {}, // app access token 无效
+		99991668: {}, // user access token 无效
+		99991677: {}, // user access token 过期
+	}
+)
+
+func isLarkErrorCode(err error, codes map[int]struct{}, status int) bool {
+	var larkErr *LarkError
+	if !errors.As(err, &larkErr) {
+		return false
+	}
+
+	if _, ok := codes[larkErr.Code]; ok {
+		return true
+	}
+
+	return larkErr.HTTPStatus == status
+}
+
+// IsRateLimited 是否触发了频率限制
+func IsRateLimited(err error) bool {
+	return isLarkErrorCode(err, rateLimitedCodes, http.StatusTooManyRequests)
+}
+
+// IsPermissionDenied 是否缺少权限
+func IsPermissionDenied(err error) bool {
+	return isLarkErrorCode(err, permissionDeniedCodes, http.StatusForbidden)
+}
+
+// IsNotFound 目标资源是否不存在
+func IsNotFound(err error) bool {
+	return isLarkErrorCode(err, notFoundCodes, http.StatusNotFound)
+}
+
+// IsTokenInvalid access token 是否无效或过期
+func IsTokenInvalid(err error) bool {
+	return isLarkErrorCode(err, tokenInvalidCodes, http.StatusUnauthorized)
+}
+
+// newLarkRespError 由接口响应构造飞书服务端报错，附带 log id 和 HTTP 状态码
+func newLarkRespError(resp *larkcore.ApiResp, code int, msg, op string) error {
+	err := &LarkError{
+		Code: code,
+		Msg:  msg,
+		Op:   op,
+	}
+
+	if resp != nil {
+		err.LogID = resp.RequestId()
+		err.HTTPStatus = resp.StatusCode
+		err.RetryAfter = parseRetryAfter(resp.Header)
+	}
+
+	return err
+}
+
+// newNotFoundError 构造本地查询不到目标时的报错
+func newNotFoundError(msg, op string) error {
+	return &LarkError{
+		Code:       CodeNotFound,
+		Msg:        msg,
+		Op:         op,
+		HTTPStatus: http.StatusNotFound,
+	}
+}
@@ -0,0 +1,178 @@
+package larki
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
+	larkdrive "github.com/larksuite/oapi-sdk-go/v3/service/drive/v1"
+)
+
+const exportFileDownloadPath = "/open-apis/drive/v1/export_tasks/file/:file_token/download"
+
+// 导出文件格式
+const (
+	ExportFormatDocx = "docx"
+	ExportFormatPdf  = "pdf"
+	ExportFormatXlsx = "xlsx"
+	ExportFormatCsv  = "csv"
+)
+
+// 导入导出任务状态
+const (
+	JobStatusSuccess    = 0
+	JobStatusInit       = 1
+	JobStatusProcessing = 2
+)
+
+// CreateExportTask 创建导出任务，docType 为 doc、docx、sheet、bitable，返回任务 ticket
+// 电子表格和多维表格导出为 csv 时需指定 subId 为子表 ID，其余情况传空
+func (c *Client) CreateExportTask(ctx context.Context, token, docType, format, subId string) (string, error) {
+	task := larkdrive.NewExportTaskBuilder().
+		Token(token).
+		Type(docType).
+		FileExtension(format)
+	if subId != "" {
+		task.SubId(subId)
+	}
+
+	req := larkdrive.NewCreateExportTaskReqBuilder().ExportTask(task.Build()).Build()
+
+	var resp *larkdrive.CreateExportTaskResp
+	err := c.retry(ctx, retryRateLimited, func() (err error) {
+		resp, err = c.Drive.ExportTask.Create(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "CreateExportTask")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return *resp.Data.Ticket, nil
+}
+
+// CreateExportTask 创建导出任务
+func CreateExportTask(ctx context.Context, token, docType, format, subId string) (string, error) {
+	return GlobalClient.CreateExportTask(ctx, token, docType, format, subId)
+}
+
+// GetExportTaskStatus 查询导出任务，token 为被导出的文档
+func (c *Client) GetExportTaskStatus(ctx context.Context, ticket, token string) (*larkdrive.ExportTask, error) {
+	req := larkdrive.NewGetExportTaskReqBuilder().Ticket(ticket).Token(token).Build()
+
+	var resp *larkdrive.GetExportTaskResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Drive.ExportTask.Get(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetExportTaskStatus")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.Result, nil
+}
+
+// GetExportTaskStatus 查询导出任务
+func GetExportTaskStatus(ctx context.Context, ticket, token string) (*larkdrive.ExportTask, error) {
+	return GlobalClient.GetExportTaskStatus(ctx, ticket, token)
+}
+
+// DownloadExportFile 流式下载导出任务生成的文件
+func (c *Client) DownloadExportFile(ctx context.Context, fileToken string, options ...DownloadOption) (*Download, error) {
+	return c.download(ctx, &larkcore.ApiReq{
+		HttpMethod: http.MethodGet,
+		ApiPath:    exportFileDownloadPath,
+		PathParams: larkcore.PathParams{"file_token": fileToken},
+	}, "DownloadExportFile", options...)
+}
+
+// DownloadExportFile 流式下载导出任务生成的文件
+func DownloadExportFile(ctx context.Context, fileToken string, options ...DownloadOption) (*Download, error) {
+	return GlobalClient.DownloadExportFile(ctx, fileToken, options...)
+}
+
+// WaitExportTask 轮询导出任务直到完成，任务失败时返回 *TaskError
+func (c *Client) WaitExportTask(ctx context.Context, ticket, token string, options ...PollOption) (*larkdrive.ExportTask, error) {
+	return PollTask(ctx, func(ctx context.Context) (*larkdrive.ExportTask, bool, error) {
+		status, err := c.GetExportTaskStatus(ctx, ticket, token)
+		if err != nil {
+			return nil, false, err
+		}
+
+		if status.JobStatus == nil {
+			return status, false, nil
+		}
+
+		switch jobStatus := *status.JobStatus; jobStatus {
+		case JobStatusSuccess:
+			return status, true, nil
+		case JobStatusInit, JobStatusProcessing:
+			return status, false, nil
+		default:
+			return status, false, &TaskError{Op: "ExportDoc", TaskId: ticket, Status: jobStatus, Msg: derefString(status.JobErrorMsg)}
+		}
+	}, options...)
+}
+
+// WaitExportTask 轮询导出任务直到完成
+func WaitExportTask(ctx context.Context, ticket, token string, options ...PollOption) (*larkdrive.ExportTask, error) {
+	return GlobalClient.WaitExportTask(ctx, ticket, token, options...)
+}
+
+// ExportDoc 导出云文档并返回导出文件的内容，依次创建导出任务、等待任务完成、下载文件
+func (c *Client) ExportDoc(ctx context.Context, token, docType, format string, options ...PollOption) (io.ReadCloser, error) {
+	return c.ExportSubDoc(ctx, token, docType, format, "", options...)
+}
+
+// ExportDoc 导出云文档并返回导出文件的内容
+func ExportDoc(ctx context.Context, token, docType, format string, options ...PollOption) (io.ReadCloser, error) {
+	return GlobalClient.ExportDoc(ctx, token, docType, format, options...)
+}
+
+// ExportSubDoc 导出电子表格或多维表格中的子表，用于导出 csv
+func (c *Client) ExportSubDoc(ctx context.Context, token, docType, format, subId string, options ...PollOption) (io.ReadCloser, error) {
+	ticket, err := c.CreateExportTask(ctx, token, docType, format, subId)
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := c.WaitExportTask(ctx, ticket, token, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	fileToken := derefString(status.FileToken)
+	if fileToken == "" {
+		return nil, fmt.Errorf("larki: export task %s finished without file token", ticket)
+	}
+
+	// 出错时返回 nil 接口而不是包装了 nil *Download 的 io.ReadCloser
+	download, err := c.DownloadExportFile(ctx, fileToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return download, nil
+}
+
+// ExportSubDoc 导出电子表格或多维表格中的子表
+func ExportSubDoc(ctx context.Context, token, docType, format, subId string, options ...PollOption) (io.ReadCloser, error) {
+	return GlobalClient.ExportSubDoc(ctx, token, docType, format, subId, options...)
+}
@@ -5,11 +5,9 @@ go 1.18
 require (
 	github.com/bytedance/sonic v1.11.0
 	github.com/larksuite/oapi-sdk-go/v3 v3.2.5
-	go.uber.org/ratelimit v0.3.1
 )
 
 require (
-	github.com/benbjohnson/clock v1.3.5 // indirect
 	github.com/chenzhuoyu/base64x v0.0.0-20230717121745-296ad89f973d // indirect
 	github.com/chenzhuoyu/iasm v0.9.0 // indirect
 	github.com/klauspost/cpuid/v2 v2.0.9 // indirect
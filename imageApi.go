@@ -10,15 +10,24 @@ import (
 
 // GetImage 下载图片
 func (c *Client) GetImage(ctx context.Context, messageId, imageKey string) (io.Reader, error) {
-	resp, err := c.Im.MessageResource.Get(ctx, larkim.NewGetMessageResourceReqBuilder().MessageId(messageId).FileKey(imageKey).Build())
+	var resp *larkim.GetMessageResourceResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Im.MessageResource.Get(ctx, larkim.NewGetMessageResourceReqBuilder().
+			MessageId(messageId).FileKey(imageKey).Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetImage")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if !resp.Success() {
-		return nil, newLarkError(resp.Code, resp.Msg, "GetImage")
-	}
-
 	reader := bytes.NewReader(resp.RawBody)
 	return reader, nil
 }
@@ -30,16 +39,31 @@ func GetImage(ctx context.Context, messageId, imageKey string) (io.Reader, error
 
 // UploadImage 上传图片
 func (c *Client) UploadImage(ctx context.Context, reader io.Reader) (string, error) {
-	resp, err := c.Im.Image.Create(ctx, larkim.NewCreateImageReqBuilder().Body(
-		larkim.NewCreateImageReqBodyBuilder().ImageType(larkim.ImageTypeMessage).Image(reader).Build()).Build())
+	rewind := rewinder(reader)
+	var resp *larkim.CreateImageResp
+	err := c.retry(ctx, bodyRetryMode(rewind, retrySafe), func() (err error) {
+		if rewind != nil {
+			if err = rewind(); err != nil {
+				return err
+			}
+		}
+
+		resp, err = c.Im.Image.Create(ctx, larkim.NewCreateImageReqBuilder().Body(
+			larkim.NewCreateImageReqBodyBuilder().ImageType(larkim.ImageTypeMessage).Image(reader).Build()).Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UploadImage")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return "", err
 	}
 
-	if !resp.Success() {
-		return "", newLarkError(resp.Code, resp.Msg, "UploadImage")
-	}
-
 	return *resp.Data.ImageKey, nil
 }
 
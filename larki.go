@@ -4,6 +4,7 @@ import (
 	"context"
 	"os"
 	"sync"
+	"time"
 
 	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
 
@@ -43,7 +44,9 @@ func NewClientFromEnv() (*Client, error) {
 
 func NewClientWithConfig(config *Config, options ...ClientOption) (*Client, error) {
 	client := &Client{
-		Config: config,
+		Config:      config,
+		retryPolicy: DefaultRetryPolicy,
+		limiters:    newLimiterSet(),
 	}
 
 	client.Client = lark.NewClient(config.AppID, config.AppSecret)
@@ -115,6 +118,22 @@ func WithCustomizedEventSubscribe(eventType string, evtChan chan *larkevent.Even
 	}
 }
 
+// WithContactCache 为通讯录查询启用缓存，cache 为 nil 时使用进程内缓存，ttl <= 0 时缓存不过期
+func WithContactCache(cache larkcore.Cache, ttl time.Duration) ClientOption {
+	return func(client *Client) {
+		if cache == nil {
+			cache = NewMemoryCache()
+		}
+
+		if ttl < 0 {
+			ttl = 0
+		}
+
+		client.contactCache = cache
+		client.contactCacheTTL = ttl
+	}
+}
+
 func WithTokenCache(cache larkcore.Cache) ClientOption {
 	return func(client *Client) {
 		client.Client = lark.NewClient(client.AppID, client.AppSecret, lark.WithTokenCache(cache))
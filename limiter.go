@@ -0,0 +1,190 @@
+package larki
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ApiFamily 接口分类，同一分类共享一个频率限制
+// 目前只有云空间列表和分片上传相关接口在调用前主动限流，其余接口依赖重试策略在被限流后退避
+type ApiFamily string
+
+const (
+	ApiDriveList          ApiFamily = "drive.list"
+	ApiDriveUploadPrepare ApiFamily = "drive.upload_prepare"
+	ApiDriveUploadPart    ApiFamily = "drive.upload_part"
+	ApiDriveUploadFinish  ApiFamily = "drive.upload_finish"
+	ApiMediaUploadPrepare ApiFamily = "media.upload_prepare"
+	ApiMediaUploadPart    ApiFamily = "media.upload_part"
+	ApiMediaUploadFinish  ApiFamily = "media.upload_finish"
+)
+
+// RateLimit 每 Per 时间内最多 Rate 次调用
+type RateLimit struct {
+	Rate int
+	Per  time.Duration
+}
+
+// PerSecond 每秒最多 rate 次调用
+func PerSecond(rate int) RateLimit {
+	return RateLimit{Rate: rate, Per: time.Second}
+}
+
+// PerMinute 每分钟最多 rate 次调用
+func PerMinute(rate int) RateLimit {
+	return RateLimit{Rate: rate, Per: time.Minute}
+}
+
+// DefaultRateLimits 各接口分类的默认频率限制，参考飞书开放平台文档
+var DefaultRateLimits = map[ApiFamily]RateLimit{
+	ApiDriveList:          PerSecond(20),
+	ApiDriveUploadPrepare: PerSecond(2),
+	ApiDriveUploadPart:    PerSecond(5),
+	ApiDriveUploadFinish:  PerSecond(2),
+	ApiMediaUploadPrepare: PerSecond(2),
+	ApiMediaUploadPart:    PerSecond(5),
+	ApiMediaUploadFinish:  PerSecond(2),
+}
+
+// Limiter 频率限制器
+type Limiter interface {
+	// Wait 阻塞直到允许下一次调用，ctx 取消时立即返回
+	Wait(ctx context.Context) error
+}
+
+// NewLimiter 构造均匀间隔的频率限制器，limit.Rate <= 0 时不限制
+func NewLimiter(limit RateLimit) Limiter {
+	if limit.Rate <= 0 || limit.Per <= 0 {
+		return unlimited{}
+	}
+
+	return &intervalLimiter{interval: limit.Per / time.Duration(limit.Rate)}
+}
+
+type unlimited struct{}
+
+func (unlimited) Wait(ctx context.Context) error {
+	return ctx.Err()
+}
+
+type intervalLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func (l *intervalLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	slot := l.next
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	if err := sleepCtx(ctx, slot.Sub(now)); err != nil {
+		// 归还未使用的时间片
+		l.mu.Lock()
+		if l.next.Equal(slot.Add(l.interval)) {
+			l.next = slot
+		}
+		l.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// WithRateLimit 设置某一接口分类的频率限制
+func WithRateLimit(family ApiFamily, limit RateLimit) ClientOption {
+	return func(client *Client) {
+		client.limiterSet().setLimit(family, limit)
+	}
+}
+
+// WithRateLimits 批量设置接口分类的频率限制
+func WithRateLimits(limits map[ApiFamily]RateLimit) ClientOption {
+	return func(client *Client) {
+		set := client.limiterSet()
+		for family, limit := range limits {
+			set.setLimit(family, limit)
+		}
+	}
+}
+
+type tenantKeyCtxKey struct{}
+
+// ContextWithTenantKey 指定调用所属的租户，不同租户使用独立的频率限制
+func ContextWithTenantKey(ctx context.Context, tenantKey string) context.Context {
+	return context.WithValue(ctx, tenantKeyCtxKey{}, tenantKey)
+}
+
+func tenantKeyFromContext(ctx context.Context) string {
+	tenantKey, _ := ctx.Value(tenantKeyCtxKey{}).(string)
+	return tenantKey
+}
+
+// limiterSet 客户端的频率限制器集合，按 租户 + 接口分类 懒加载
+type limiterSet struct {
+	mu       sync.Mutex
+	limits   map[ApiFamily]RateLimit
+	limiters map[string]Limiter
+}
+
+func newLimiterSet() *limiterSet {
+	limits := make(map[ApiFamily]RateLimit, len(DefaultRateLimits))
+	for family, limit := range DefaultRateLimits {
+		limits[family] = limit
+	}
+
+	return &limiterSet{
+		limits:   limits,
+		limiters: make(map[string]Limiter),
+	}
+}
+
+func (s *limiterSet) setLimit(family ApiFamily, limit RateLimit) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.limits[family] = limit
+	for key := range s.limiters {
+		if strings.HasSuffix(key, "/"+string(family)) {
+			delete(s.limiters, key)
+		}
+	}
+}
+
+func (s *limiterSet) get(tenantKey string, family ApiFamily) Limiter {
+	key := tenantKey + "/" + string(family)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	limiter, ok := s.limiters[key]
+	if !ok {
+		limiter = NewLimiter(s.limits[family])
+		s.limiters[key] = limiter
+	}
+
+	return limiter
+}
+
+// limiterSet 返回客户端的频率限制器集合，未通过 NewClient 构造的客户端懒加载默认配置
+func (c *Client) limiterSet() *limiterSet {
+	c.limitersOnce.Do(func() {
+		if c.limiters == nil {
+			c.limiters = newLimiterSet()
+		}
+	})
+
+	return c.limiters
+}
+
+// wait 等待接口分类的频率限制
+func (c *Client) wait(ctx context.Context, family ApiFamily) error {
+	return c.limiterSet().get(tenantKeyFromContext(ctx), family).Wait(ctx)
+}
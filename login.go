@@ -37,7 +37,7 @@ func (c *Client) GetJsApiTicket(ctx context.Context) (*JsApiTicket, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%s: %d", "Error fetching js api ticket", resp.StatusCode)
+		return nil, newLarkRespError(resp, 0, http.StatusText(resp.StatusCode), "GetJsApiTicket")
 	}
 
 	var ticketResp JsApiTicketResponse
@@ -46,7 +46,7 @@ func (c *Client) GetJsApiTicket(ctx context.Context) (*JsApiTicket, error) {
 	}
 
 	if ticketResp.Code != 0 {
-		return nil, fmt.Errorf("%s: [%d] %s", "Error fetching js api ticket", ticketResp.Code, ticketResp.Message)
+		return nil, newLarkRespError(resp, ticketResp.Code, ticketResp.Message, "GetJsApiTicket")
 	}
 
 	return &ticketResp.Data, nil
@@ -91,7 +91,7 @@ func (c *Client) GetMiniProgramUserAccessToken(ctx context.Context, code string)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("%s: %d", "Error fetching mini program token", resp.StatusCode)
+		return nil, newLarkRespError(resp, 0, http.StatusText(resp.StatusCode), "GetMiniProgramUserAccessToken")
 	}
 
 	var miniProgTokenResp MiniProgramTokenResponse
@@ -101,7 +101,7 @@ func (c *Client) GetMiniProgramUserAccessToken(ctx context.Context, code string)
 	}
 
 	if miniProgTokenResp.Code != 0 {
-		return nil, fmt.Errorf("%s: [%d] %s", "Error fetching mini program token", miniProgTokenResp.Code, miniProgTokenResp.Message)
+		return nil, newLarkRespError(resp, miniProgTokenResp.Code, miniProgTokenResp.Message, "GetMiniProgramUserAccessToken")
 	}
 
 	return &miniProgTokenResp.Data, nil
@@ -0,0 +1,463 @@
+package larki
+
+import (
+	"context"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	larkcontact "github.com/larksuite/oapi-sdk-go/v3/service/contact/v3"
+)
+
+// codeLanguages 飞书代码块语言枚举与 Markdown 代码块语言标记的对应关系
+var codeLanguages = map[int]string{
+	1: "", 2: "abap", 3: "ada", 4: "apache", 5: "apex", 6: "assembly", 7: "bash", 8: "csharp",
+	9: "cpp", 10: "c", 11: "cobol", 12: "css", 13: "coffeescript", 14: "d", 15: "dart", 16: "delphi",
+	17: "django", 18: "dockerfile", 19: "erlang", 20: "fortran", 21: "foxpro", 22: "go", 23: "groovy", 24: "html",
+	25: "htmlbars", 26: "http", 27: "haskell", 28: "json", 29: "java", 30: "javascript", 31: "julia", 32: "kotlin",
+	33: "latex", 34: "lisp", 35: "logo", 36: "lua", 37: "matlab", 38: "makefile", 39: "markdown", 40: "nginx",
+	41: "objectivec", 42: "openedgeabl", 43: "php", 44: "perl", 45: "postscript", 46: "powershell", 47: "prolog", 48: "protobuf",
+	49: "python", 50: "r", 51: "rpg", 52: "ruby", 53: "rust", 54: "sas", 55: "scss", 56: "sql",
+	57: "scala", 58: "scheme", 59: "scratch", 60: "shell", 61: "swift", 62: "thrift", 63: "typescript", 64: "vbscript",
+	65: "vb", 66: "xml", 67: "yaml", 68: "cmake", 69: "diff", 70: "gherkin", 71: "graphql", 72: "glsl",
+	73: "properties", 74: "solidity", 75: "toml",
+}
+
+var (
+	// markdownEscaper 转义文本中会被识别为行内样式的字符
+	markdownEscaper = strings.NewReplacer(
+		`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `~`, `\~`,
+		`[`, `\[`, `]`, `\]`, `<`, `\<`, `|`, `\|`, `$`, `\$`,
+	)
+	// markdownLineStart 行首会被识别为标题、列表、引用、分隔线的字符
+	markdownLineStart = regexp.MustCompile(`(?m)^([ \t]*\d*)([#>+=.)-])`)
+)
+
+// MarkdownOption Markdown 导出配置
+type MarkdownOption func(*markdownOptions)
+
+type markdownOptions struct {
+	assetPrefix    string
+	assetPrefixSet bool
+	skipTitle      bool
+}
+
+// WithMarkdownAssetPrefix 设置 Markdown 中引用图片的路径前缀，默认为 assetDir
+func WithMarkdownAssetPrefix(prefix string) MarkdownOption {
+	return func(o *markdownOptions) {
+		o.assetPrefix = prefix
+		o.assetPrefixSet = true
+	}
+}
+
+// WithMarkdownSkipTitle 不输出文档标题
+func WithMarkdownSkipTitle() MarkdownOption {
+	return func(o *markdownOptions) {
+		o.skipTitle = true
+	}
+}
+
+type markdownRenderer struct {
+	client   *Client
+	ctx      context.Context
+	tree     *DocxTree
+	assetDir string
+	opts     *markdownOptions
+	users    map[string]string
+}
+
+// ExportDocxMarkdown 将云文档转换为 Markdown，图片下载到 assetDir 并以相对路径引用
+func (c *Client) ExportDocxMarkdown(ctx context.Context, docToken, assetDir string, options ...MarkdownOption) (string, error) {
+	tree, err := c.GetDocxTree(ctx, docToken)
+	if err != nil {
+		return "", err
+	}
+
+	return c.RenderDocxMarkdown(ctx, tree, assetDir, options...)
+}
+
+// ExportDocxMarkdown 将云文档转换为 Markdown
+func ExportDocxMarkdown(ctx context.Context, docToken, assetDir string, options ...MarkdownOption) (string, error) {
+	return GlobalClient.ExportDocxMarkdown(ctx, docToken, assetDir, options...)
+}
+
+// RenderDocxMarkdown 将已获取的 Block 树转换为 Markdown
+func (c *Client) RenderDocxMarkdown(ctx context.Context, tree *DocxTree, assetDir string, options ...MarkdownOption) (string, error) {
+	opts := &markdownOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+
+	if !opts.assetPrefixSet {
+		opts.assetPrefix = filepath.ToSlash(assetDir)
+	}
+
+	r := &markdownRenderer{
+		client:   c,
+		ctx:      ctx,
+		tree:     tree,
+		assetDir: assetDir,
+		opts:     opts,
+		users:    make(map[string]string),
+	}
+
+	body, err := r.blocks(tree.Children(tree.Root))
+	if err != nil {
+		return "", err
+	}
+
+	if !opts.skipTitle {
+		if title := r.inlines(tree.Root.Title); title != "" {
+			body = "# " + title + "\n\n" + body
+		}
+	}
+
+	return body + "\n", nil
+}
+
+// RenderDocxMarkdown 将已获取的 Block 树转换为 Markdown
+func RenderDocxMarkdown(ctx context.Context, tree *DocxTree, assetDir string, options ...MarkdownOption) (string, error) {
+	return GlobalClient.RenderDocxMarkdown(ctx, tree, assetDir, options...)
+}
+
+// blocks 渲染同一层级的 Block，相邻列表项之间不空行
+func (r *markdownRenderer) blocks(blocks []DocxBlock) (string, error) {
+	var builder strings.Builder
+	lastList := false
+
+	for _, block := range blocks {
+		text, err := r.block(block)
+		if err != nil {
+			return "", err
+		}
+
+		if text == "" {
+			continue
+		}
+
+		isList := isListBlock(block)
+		if builder.Len() > 0 {
+			if isList && lastList {
+				builder.WriteString("\n")
+			} else {
+				builder.WriteString("\n\n")
+			}
+		}
+
+		builder.WriteString(text)
+		lastList = isList
+	}
+
+	return builder.String(), nil
+}
+
+func isListBlock(block DocxBlock) bool {
+	switch block.(type) {
+	case *ListBlock, *TodoBlock:
+		return true
+	}
+
+	return false
+}
+
+func (r *markdownRenderer) block(block DocxBlock) (string, error) {
+	switch b := block.(type) {
+	case *TextBlock:
+		return escapeLineStart(r.inlines(b.Elements)), nil
+	case *HeadingBlock:
+		return strings.Repeat("#", minInt(b.level(), 6)) + " " + r.inlines(b.Elements), nil
+	case *ListBlock:
+		marker := "- "
+		if b.Ordered {
+			marker = "1. "
+		}
+		return r.listItem(b, marker, b.Elements)
+	case *TodoBlock:
+		marker := "- [ ] "
+		if b.Done {
+			marker = "- [x] "
+		}
+		return r.listItem(b, marker, b.Elements)
+	case *QuoteBlock:
+		return prefixLines(escapeLineStart(r.inlines(b.Elements)), "> "), nil
+	case *CodeBlock:
+		code := InlinesText(b.Elements)
+		fence := strings.Repeat("`", maxInt(3, longestRun(code, '`')+1))
+		return fence + codeLanguages[b.Language] + "\n" + code + "\n" + fence, nil
+	case *DividerBlock:
+		return "---", nil
+	case *ImageBlock:
+		return r.image(b)
+	case *TableBlock:
+		return r.table(b)
+	case *CalloutBlock, *QuoteContainerBlock:
+		text, err := r.blocks(r.tree.Children(block))
+		if err != nil {
+			return "", err
+		}
+
+		return prefixLines(text, "> "), nil
+	}
+
+	// 分栏等容器只输出其内容
+	return r.blocks(r.tree.Children(block))
+}
+
+func (r *markdownRenderer) listItem(block DocxBlock, marker string, elements []Inline) (string, error) {
+	text := marker + escapeLineStart(r.inlines(elements))
+
+	children := r.tree.Children(block)
+	if len(children) == 0 {
+		return text, nil
+	}
+
+	nested, err := r.blocks(children)
+	if err != nil {
+		return "", err
+	}
+
+	return text + "\n" + prefixLines(nested, strings.Repeat(" ", len(marker))), nil
+}
+
+func (r *markdownRenderer) table(block *TableBlock) (string, error) {
+	if block.Rows == 0 || block.Columns == 0 {
+		return "", nil
+	}
+
+	var builder strings.Builder
+	for row := 0; row < block.Rows; row++ {
+		builder.WriteString("|")
+		for column := 0; column < block.Columns; column++ {
+			cell := ""
+			if id := block.Cell(row, column); id != "" {
+				if cellBlock := r.tree.Block(id); cellBlock != nil {
+					text, err := r.blocks(r.tree.Children(cellBlock))
+					if err != nil {
+						return "", err
+					}
+
+					cell = strings.ReplaceAll(escapeTablePipes(text), "\n\n", "<br>")
+					cell = strings.ReplaceAll(cell, "\n", "<br>")
+				}
+			}
+
+			builder.WriteString(" " + cell + " |")
+		}
+
+		if row == 0 {
+			builder.WriteString("\n|")
+			builder.WriteString(strings.Repeat(" --- |", block.Columns))
+		}
+
+		if row < block.Rows-1 {
+			builder.WriteString("\n")
+		}
+	}
+
+	return builder.String(), nil
+}
+
+// image 下载图片到 assetDir，已存在时不重复下载
+func (r *markdownRenderer) image(block *ImageBlock) (string, error) {
+	if block.Token == "" {
+		return "", nil
+	}
+
+	if err := os.MkdirAll(r.assetDir, 0o755); err != nil {
+		return "", err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(r.assetDir, block.Token+".*"))
+	if err != nil {
+		return "", err
+	}
+
+	// 跳过中断的 writeFileAtomic 留下的临时文件
+	var name string
+	for _, match := range matches {
+		if !strings.HasSuffix(match, ".tmp") {
+			name = filepath.Base(match)
+			break
+		}
+	}
+
+	if name == "" {
+		reader, fileName, err := r.client.GetDocMedia(r.ctx, block.Token)
+		if err != nil {
+			return "", err
+		}
+
+		ext := filepath.Ext(fileName)
+		if ext == "" {
+			ext = ".png"
+		}
+
+		name = block.Token + ext
+		if err = writeFileAtomic(filepath.Join(r.assetDir, name), reader); err != nil {
+			return "", err
+		}
+	}
+
+	return "![](" + path.Join(r.opts.assetPrefix, name) + ")", nil
+}
+
+func (r *markdownRenderer) inlines(elements []Inline) string {
+	var builder strings.Builder
+	for _, element := range elements {
+		builder.WriteString(r.inline(element))
+	}
+
+	return builder.String()
+}
+
+func (r *markdownRenderer) inline(element Inline) string {
+	var text string
+	switch element.Kind {
+	case InlineText:
+		text = element.Text
+	case InlineMentionUser:
+		return "@" + markdownEscaper.Replace(r.userName(element.UserId))
+	case InlineMentionDoc:
+		return "[" + markdownEscaper.Replace(element.Text) + "](" + element.DocUrl + ")"
+	case InlineEquation:
+		return "$" + strings.TrimSpace(element.Text) + "$"
+	default:
+		return ""
+	}
+
+	// 样式标记不能包含首尾空白，否则 Markdown 不会识别
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+
+	leading := text[:strings.Index(text, trimmed)]
+	trailing := text[len(leading)+len(trimmed):]
+
+	if element.Code {
+		// 反引号串比内容中最长的更长，内容首尾为反引号时以空格隔开
+		fence := strings.Repeat("`", longestRun(trimmed, '`')+1)
+		if strings.HasPrefix(trimmed, "`") || strings.HasSuffix(trimmed, "`") {
+			trimmed = " " + trimmed + " "
+		}
+		trimmed = fence + trimmed + fence
+	} else {
+		trimmed = markdownEscaper.Replace(trimmed)
+	}
+
+	if element.Bold {
+		trimmed = "**" + trimmed + "**"
+	}
+
+	if element.Italic {
+		trimmed = "*" + trimmed + "*"
+	}
+
+	if element.Strikethrough {
+		trimmed = "~~" + trimmed + "~~"
+	}
+
+	if element.Link != "" {
+		trimmed = "[" + trimmed + "](" + element.Link + ")"
+	}
+
+	return leading + trimmed + trailing
+}
+
+// userName 获取被提及用户的名字，无权限时使用 open_id
+func (r *markdownRenderer) userName(openId string) string {
+	if name, ok := r.users[openId]; ok {
+		return name
+	}
+
+	name := openId
+	if user, err := r.client.GetUser(r.ctx, openId, larkcontact.UserIdTypeOpenId); err == nil && derefString(user.Name) != "" {
+		name = derefString(user.Name)
+	}
+
+	r.users[openId] = name
+	return name
+}
+
+// escapeLineStart 转义行首会被识别为块级语法的字符
+func escapeLineStart(text string) string {
+	return markdownLineStart.ReplaceAllString(text, `$1\$2`)
+}
+
+// escapeTablePipes 转义表格单元格中尚未转义的 |
+func escapeTablePipes(text string) string {
+	var builder strings.Builder
+	backslashes := 0
+	for i := 0; i < len(text); i++ {
+		if text[i] == '|' && backslashes%2 == 0 {
+			builder.WriteByte('\\')
+		}
+
+		if text[i] == '\\' {
+			backslashes++
+		} else {
+			backslashes = 0
+		}
+
+		builder.WriteByte(text[i])
+	}
+
+	return builder.String()
+}
+
+// longestRun 文本中字符 c 最长的连续长度
+func longestRun(text string, c byte) int {
+	longest, run := 0, 0
+	for i := 0; i < len(text); i++ {
+		if text[i] != c {
+			run = 0
+			continue
+		}
+
+		if run++; run > longest {
+			longest = run
+		}
+	}
+
+	return longest
+}
+
+// prefixLines 为每一行添加前缀，空行只保留去除尾部空白的前缀
+func prefixLines(text, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// writeFileAtomic 先写入临时文件再重命名，临时文件名唯一，并发写入同一文件时不会互相覆盖
+func writeFileAtomic(name string, reader io.Reader) error {
+	file, err := os.CreateTemp(filepath.Dir(name), filepath.Base(name)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	tmp := file.Name()
+	if err = file.Chmod(0o644); err == nil {
+		_, err = io.Copy(file, reader)
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, name)
+}
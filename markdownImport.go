@@ -0,0 +1,1033 @@
+package larki
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	larkdocx "github.com/larksuite/oapi-sdk-go/v3/service/docx/v1"
+)
+
+// codeLanguageAliases Markdown 代码块中常见的语言别名
+var codeLanguageAliases = map[string]int{
+	"text": 1, "plaintext": 1, "txt": 1, "sh": 60, "zsh": 60, "console": 60, "cs": 8, "c#": 8,
+	"c++": 9, "cc": 9, "h": 10, "golang": 22, "js": 30, "jsx": 30, "kt": 32, "md": 39,
+	"objc": 41, "py": 49, "rb": 52, "rs": 53, "ts": 63, "tsx": 63, "yml": 67, "proto": 48,
+	"ps1": 46, "make": 38,
+}
+
+var (
+	markdownHeading   = regexp.MustCompile(`^(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	markdownImage     = regexp.MustCompile(`^!\[([^\]]*)\]\(\s*<?([^)\s>]+)>?(?:\s+"[^"]*")?\s*\)$`)
+	markdownTableSep  = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	markdownLineBreak = regexp.MustCompile(`(?i)<br\s*/?>`)
+)
+
+// DocxNode 待写入云文档的 Block 及其子节点
+type DocxNode struct {
+	Block    DocxBlock
+	Children []*DocxNode
+	// ImageSrc 图片的本地路径或 http(s) 地址，仅图片 Block 使用
+	ImageSrc string
+}
+
+// ParseMarkdown 将 Markdown 解析为待写入的 Block 树
+// 支持标题、段落、列表、待办、引用、代码块、表格、分割线、图片及常见的行内样式和链接
+func ParseMarkdown(markdown string) []*DocxNode {
+	markdown = strings.ReplaceAll(markdown, "\r\n", "\n")
+	lines := strings.Split(markdown, "\n")
+	for i, line := range lines {
+		lines[i] = expandTabs(line)
+	}
+
+	return parseMarkdownBlocks(lines)
+}
+
+// DocxWriteOption Markdown 写入配置
+type DocxWriteOption func(*docxWriteOptions)
+
+type docxWriteOptions struct {
+	remoteImages bool
+	httpClient   *http.Client
+	imageRoot    string
+}
+
+// WithRemoteImages 允许下载 http(s) 地址的图片，client 为 nil 时使用 http.DefaultClient
+// 默认只读取 baseDir 下的本地图片
+func WithRemoteImages(client *http.Client) DocxWriteOption {
+	return func(o *docxWriteOptions) {
+		o.remoteImages = true
+		o.httpClient = client
+	}
+}
+
+// WithImageRoot 允许引用 root 下的本地图片，默认为 baseDir，用于引用上级目录中的公共图片
+func WithImageRoot(root string) DocxWriteOption {
+	return func(o *docxWriteOptions) {
+		o.imageRoot = root
+	}
+}
+
+func newDocxWriter(c *Client, docToken, baseDir string, options []DocxWriteOption) *docxWriter {
+	opts := &docxWriteOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+
+	return &docxWriter{client: c, docToken: docToken, baseDir: baseDir, opts: opts}
+}
+
+// CreateDocxFromMarkdown 在 folderToken 下创建云文档并直接写入 Markdown 内容
+// title 为空且 Markdown 以一级标题开头时使用该标题；图片路径相对于 baseDir
+func (c *Client) CreateDocxFromMarkdown(ctx context.Context, folderToken, title, markdown, baseDir string, options ...DocxWriteOption) (*larkdocx.Document, error) {
+	nodes := ParseMarkdown(markdown)
+	if title == "" && len(nodes) > 0 {
+		if heading, ok := nodes[0].Block.(*HeadingBlock); ok && heading.Level == 1 {
+			title = InlinesText(heading.Elements)
+			nodes = nodes[1:]
+		}
+	}
+
+	doc, err := c.CreateDocx(ctx, folderToken, title)
+	if err != nil {
+		return nil, err
+	}
+
+	// 文档根 Block 的 ID 与文档 token 相同
+	_, err = c.WriteDocxNodes(ctx, *doc.DocumentId, *doc.DocumentId, -1, nodes, baseDir, options...)
+	return doc, err
+}
+
+// CreateDocxFromMarkdown 创建云文档并写入 Markdown 内容
+func CreateDocxFromMarkdown(ctx context.Context, folderToken, title, markdown, baseDir string, options ...DocxWriteOption) (*larkdocx.Document, error) {
+	return GlobalClient.CreateDocxFromMarkdown(ctx, folderToken, title, markdown, baseDir, options...)
+}
+
+// WriteDocxMarkdown 将 Markdown 写入 parentId 的第 index 个子 Block 前，index 为 -1 时追加到末尾
+func (c *Client) WriteDocxMarkdown(ctx context.Context, docToken, parentId string, index int, markdown, baseDir string, options ...DocxWriteOption) ([]DocxBlock, error) {
+	return c.WriteDocxNodes(ctx, docToken, parentId, index, ParseMarkdown(markdown), baseDir, options...)
+}
+
+// WriteDocxMarkdown 将 Markdown 写入已有云文档
+func WriteDocxMarkdown(ctx context.Context, docToken, parentId string, index int, markdown, baseDir string, options ...DocxWriteOption) ([]DocxBlock, error) {
+	return GlobalClient.WriteDocxMarkdown(ctx, docToken, parentId, index, markdown, baseDir, options...)
+}
+
+// WriteDocxNodes 将 nodes 写入 parentId 的第 index 个子 Block 前，index 为 -1 时追加到末尾，返回创建的顶层 Block
+// 图片从 baseDir 下的相对路径读取，启用 WithRemoteImages 时也可以是 http(s) 地址，以 docx_image 素材上传
+// 出错时已写入的内容不会回滚
+func (c *Client) WriteDocxNodes(ctx context.Context, docToken, parentId string, index int, nodes []*DocxNode, baseDir string, options ...DocxWriteOption) ([]DocxBlock, error) {
+	return newDocxWriter(c, docToken, baseDir, options).write(ctx, parentId, index, nodes)
+}
+
+// WriteDocxNodes 将 nodes 写入已有云文档
+func WriteDocxNodes(ctx context.Context, docToken, parentId string, index int, nodes []*DocxNode, baseDir string, options ...DocxWriteOption) ([]DocxBlock, error) {
+	return GlobalClient.WriteDocxNodes(ctx, docToken, parentId, index, nodes, baseDir, options...)
+}
+
+type docxWriter struct {
+	client   *Client
+	docToken string
+	baseDir  string
+	opts     *docxWriteOptions
+}
+
+func (w *docxWriter) write(ctx context.Context, parentId string, index int, nodes []*DocxNode) ([]DocxBlock, error) {
+	created := make([]DocxBlock, 0, len(nodes))
+	for start := 0; start < len(nodes); {
+		// 图片需要先创建 Block 再上传素材，单独写入
+		if nodes[start].ImageSrc != "" {
+			image, err := w.image(ctx, parentId, index, nodes[start].ImageSrc)
+			if err != nil {
+				return created, err
+			}
+
+			created = append(created, image)
+			index = advanceDocxIndex(index, 1)
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(nodes) && nodes[end].ImageSrc == "" {
+			end++
+		}
+
+		batch := nodes[start:end]
+		blocks := make([]DocxBlock, 0, len(batch))
+		for _, node := range batch {
+			blocks = append(blocks, node.Block)
+		}
+
+		result, err := w.client.CreateDocxBlocks(ctx, w.docToken, parentId, index, blocks...)
+		if err != nil {
+			return created, err
+		}
+
+		if len(result) != len(batch) {
+			return created, fmt.Errorf("larki: created %d blocks, expected %d", len(result), len(batch))
+		}
+
+		for i, block := range result {
+			if err = w.children(ctx, block, batch[i]); err != nil {
+				return created, err
+			}
+		}
+
+		created = append(created, result...)
+		index = advanceDocxIndex(index, len(batch))
+		start = end
+	}
+
+	return created, nil
+}
+
+// children 写入容器的子节点，并删除服务端自动创建的空段落
+func (w *docxWriter) children(ctx context.Context, block DocxBlock, node *DocxNode) error {
+	if table, ok := block.(*TableBlock); ok {
+		for i, cell := range node.Children {
+			if i >= len(table.Cells) || len(cell.Children) == 0 {
+				continue
+			}
+
+			// 单元格创建时自带一个空段落
+			if err := w.replaceChildren(ctx, table.Cells[i], 1, cell.Children); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	if len(node.Children) == 0 {
+		return nil
+	}
+
+	return w.replaceChildren(ctx, block.Base().Id, len(block.Base().Children), node.Children)
+}
+
+func (w *docxWriter) replaceChildren(ctx context.Context, parentId string, existing int, nodes []*DocxNode) error {
+	if _, err := w.write(ctx, parentId, 0, nodes); err != nil {
+		return err
+	}
+
+	if existing == 0 {
+		return nil
+	}
+
+	return w.client.DeleteDocxBlocks(ctx, w.docToken, parentId, len(nodes), len(nodes)+existing)
+}
+
+func (w *docxWriter) image(ctx context.Context, parentId string, index int, src string) (*ImageBlock, error) {
+	name, data, err := w.loadImage(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.client.InsertDocxImage(ctx, w.docToken, parentId, index, name, len(data), bytes.NewReader(data))
+}
+
+func advanceDocxIndex(index, n int) int {
+	if index < 0 {
+		return index
+	}
+
+	return index + n
+}
+
+// loadImage 读取 Markdown 中引用的图片，本地图片必须位于 baseDir 下
+func (w *docxWriter) loadImage(ctx context.Context, src string) (string, []byte, error) {
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		if !w.opts.remoteImages {
+			return "", nil, fmt.Errorf("larki: remote image %s is not allowed without WithRemoteImages", src)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+		if err != nil {
+			return "", nil, err
+		}
+
+		client := w.opts.httpClient
+		if client == nil {
+			client = http.DefaultClient
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return "", nil, fmt.Errorf("larki: fetch image %s: %s", src, resp.Status)
+		}
+
+		data, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", nil, err
+		}
+
+		name := path.Base(req.URL.Path)
+		if name == "/" || name == "." {
+			name = "image.png"
+		}
+
+		return name, data, nil
+	}
+
+	root := w.opts.imageRoot
+	if root == "" {
+		root = w.baseDir
+	}
+
+	name, err := resolveLocalImage(src, w.baseDir, root)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return filepath.Base(name), data, nil
+}
+
+// resolveLocalImage 将图片相对 baseDir 的路径解析为文件，绝对路径及解析符号链接后位于 root 之外的路径均被拒绝
+func resolveLocalImage(src, baseDir, root string) (string, error) {
+	name, err := url.PathUnescape(src)
+	if err != nil {
+		name = src
+	}
+
+	name = filepath.FromSlash(name)
+	if filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("larki: image %s must be a relative path", src)
+	}
+
+	if baseDir == "" {
+		baseDir = "."
+	}
+
+	if root == "" {
+		root = "."
+	}
+
+	rootPath, err := filepath.Abs(root)
+	if err != nil {
+		return "", err
+	}
+
+	target, err := filepath.Abs(filepath.Join(baseDir, name))
+	if err != nil {
+		return "", err
+	}
+
+	outside := fmt.Errorf("larki: image %s is outside %s", src, root)
+	if !withinDir(rootPath, target) {
+		return "", outside
+	}
+
+	// 符号链接可能指向 root 之外
+	if rootPath, err = filepath.EvalSymlinks(rootPath); err != nil {
+		return "", err
+	}
+
+	if target, err = filepath.EvalSymlinks(target); err != nil {
+		return "", err
+	}
+
+	if !withinDir(rootPath, target) {
+		return "", outside
+	}
+
+	return target, nil
+}
+
+// withinDir target 是否位于 root 下，两者均为绝对路径
+func withinDir(root, target string) bool {
+	rel, err := filepath.Rel(root, target)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// codeLanguageOf 由代码块的语言标记得到飞书的语言枚举，未知语言为纯文本
+func codeLanguageOf(info string) int {
+	info = strings.ToLower(info)
+	if language, ok := codeLanguageAliases[info]; ok {
+		return language
+	}
+
+	for language, name := range codeLanguages {
+		if name != "" && name == info {
+			return language
+		}
+	}
+
+	return 1
+}
+
+func expandTabs(line string) string {
+	if !strings.Contains(line, "\t") {
+		return line
+	}
+
+	var builder strings.Builder
+	column := 0
+	for _, r := range line {
+		if r == '\t' {
+			spaces := 4 - column%4
+			builder.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+			continue
+		}
+
+		builder.WriteRune(r)
+		column++
+	}
+
+	return builder.String()
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func parseMarkdownBlocks(lines []string) []*DocxNode {
+	var nodes []*DocxNode
+	for i := 0; i < len(lines); {
+		trimmed := strings.TrimSpace(lines[i])
+		switch {
+		case trimmed == "":
+			i++
+		case isCodeFence(trimmed):
+			var node *DocxNode
+			node, i = parseCodeFence(lines, i)
+			nodes = append(nodes, node)
+		case markdownHeading.MatchString(trimmed):
+			match := markdownHeading.FindStringSubmatch(trimmed)
+			nodes = append(nodes, &DocxNode{Block: &HeadingBlock{Level: len(match[1]), Elements: parseInlines(match[2])}})
+			i++
+		case isThematicBreak(trimmed):
+			nodes = append(nodes, &DocxNode{Block: &DividerBlock{}})
+			i++
+		case strings.HasPrefix(trimmed, ">"):
+			var node *DocxNode
+			node, i = parseQuote(lines, i)
+			nodes = append(nodes, node)
+		case isListItem(lines[i]):
+			var node *DocxNode
+			node, i = parseListItem(lines, i)
+			nodes = append(nodes, node)
+		case isTableStart(lines, i):
+			var node *DocxNode
+			node, i = parseTable(lines, i)
+			nodes = append(nodes, node)
+		default:
+			var paragraph []*DocxNode
+			paragraph, i = parseParagraph(lines, i)
+			nodes = append(nodes, paragraph...)
+		}
+	}
+
+	return nodes
+}
+
+// isBlockStart 判断一行是否会打断正在解析的段落
+func isBlockStart(lines []string, i int) bool {
+	trimmed := strings.TrimSpace(lines[i])
+	return trimmed == "" ||
+		isCodeFence(trimmed) ||
+		markdownHeading.MatchString(trimmed) ||
+		isThematicBreak(trimmed) ||
+		strings.HasPrefix(trimmed, ">") ||
+		isListItem(lines[i]) ||
+		isTableStart(lines, i)
+}
+
+func isCodeFence(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~")
+}
+
+func parseCodeFence(lines []string, i int) (*DocxNode, int) {
+	indent := indentOf(lines[i])
+	trimmed := strings.TrimSpace(lines[i])
+	fence := trimmed[:countRun(trimmed, trimmed[0])]
+	info := strings.Fields(trimmed[len(fence):])
+
+	var code []string
+	for i++; i < len(lines); i++ {
+		if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) && strings.Trim(strings.TrimSpace(lines[i]), fence[:1]) == "" {
+			i++
+			break
+		}
+
+		line := lines[i]
+		line = line[minInt(indent, indentOf(line)):]
+		code = append(code, line)
+	}
+
+	block := &CodeBlock{Language: 1}
+	if len(info) > 0 {
+		block.Language = codeLanguageOf(info[0])
+	}
+
+	if content := strings.Join(code, "\n"); content != "" {
+		block.Elements = []Inline{PlainText(content)}
+	}
+
+	return &DocxNode{Block: block}, i
+}
+
+func isThematicBreak(trimmed string) bool {
+	compact := strings.ReplaceAll(trimmed, " ", "")
+	if len(compact) < 3 {
+		return false
+	}
+
+	switch compact[0] {
+	case '-', '*', '_':
+		return strings.Count(compact, compact[:1]) == len(compact)
+	}
+
+	return false
+}
+
+func parseQuote(lines []string, i int) (*DocxNode, int) {
+	var inner []string
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if !strings.HasPrefix(trimmed, ">") {
+			// 段落的延续行可以省略 >
+			if trimmed == "" || len(inner) == 0 || strings.TrimSpace(inner[len(inner)-1]) == "" || isBlockStart(lines, i) {
+				break
+			}
+
+			inner = append(inner, trimmed)
+			continue
+		}
+
+		trimmed = strings.TrimPrefix(trimmed[1:], " ")
+		inner = append(inner, trimmed)
+	}
+
+	return &DocxNode{Block: &QuoteContainerBlock{}, Children: parseMarkdownBlocks(inner)}, i
+}
+
+// listMarker 解析列表项标记，返回内容起始列和内容
+func listMarker(line string) (ordered bool, contentIndent int, content string, ok bool) {
+	indent := indentOf(line)
+	rest := line[indent:]
+	if rest == "" {
+		return false, 0, "", false
+	}
+
+	width := 0
+	switch rest[0] {
+	case '-', '*', '+':
+		width = 1
+	default:
+		digits := 0
+		for digits < len(rest) && digits < 9 && rest[digits] >= '0' && rest[digits] <= '9' {
+			digits++
+		}
+
+		if digits == 0 || digits >= len(rest) || (rest[digits] != '.' && rest[digits] != ')') {
+			return false, 0, "", false
+		}
+
+		ordered = true
+		width = digits + 1
+	}
+
+	if width < len(rest) && rest[width] != ' ' {
+		return false, 0, "", false
+	}
+
+	spaces := countRun(rest[width:], ' ')
+	if spaces == 0 || spaces > 4 || width+spaces == len(rest) {
+		spaces = 1
+	}
+
+	return ordered, indent + width + spaces, strings.TrimSpace(rest[minInt(width+spaces, len(rest)):]), true
+}
+
+func isListItem(line string) bool {
+	_, _, _, ok := listMarker(line)
+	return ok
+}
+
+func parseListItem(lines []string, i int) (*DocxNode, int) {
+	ordered, contentIndent, content, _ := listMarker(lines[i])
+	item := []string{content}
+	for i++; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			// 空行之后仍缩进的内容属于当前列表项
+			next := i + 1
+			for next < len(lines) && strings.TrimSpace(lines[next]) == "" {
+				next++
+			}
+
+			if next >= len(lines) || indentOf(lines[next]) < contentIndent {
+				break
+			}
+
+			item = append(item, "")
+			continue
+		}
+
+		if indentOf(line) >= contentIndent {
+			item = append(item, line[contentIndent:])
+			continue
+		}
+
+		// 段落的延续行可以不缩进
+		if strings.TrimSpace(item[len(item)-1]) != "" && !isBlockStart(lines, i) {
+			item = append(item, strings.TrimSpace(line))
+			continue
+		}
+
+		break
+	}
+
+	// 开头连续的行是列表项文本，其余为子节点
+	text := 1
+	for text < len(item) && !isBlockStart(item, text) {
+		text++
+	}
+
+	var block DocxBlock
+	first := item[0]
+	switch {
+	case strings.HasPrefix(first, "[ ] "), first == "[ ]":
+		block = &TodoBlock{Elements: parseInlines(joinParagraph(trimTaskMarker(item[:text])))}
+	case strings.HasPrefix(first, "[x] "), strings.HasPrefix(first, "[X] "), first == "[x]", first == "[X]":
+		block = &TodoBlock{Done: true, Elements: parseInlines(joinParagraph(trimTaskMarker(item[:text])))}
+	default:
+		block = &ListBlock{Ordered: ordered, Elements: parseInlines(joinParagraph(item[:text]))}
+	}
+
+	return &DocxNode{Block: block, Children: parseMarkdownBlocks(item[text:])}, i
+}
+
+func trimTaskMarker(lines []string) []string {
+	return append([]string{strings.TrimSpace(lines[0][3:])}, lines[1:]...)
+}
+
+func isTableStart(lines []string, i int) bool {
+	return i+1 < len(lines) &&
+		strings.Contains(lines[i], "|") &&
+		strings.Contains(lines[i+1], "|") &&
+		markdownTableSep.MatchString(strings.TrimSpace(lines[i+1]))
+}
+
+func parseTable(lines []string, i int) (*DocxNode, int) {
+	header := splitTableRow(lines[i])
+	rows := [][]string{header}
+	for i += 2; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "" || !strings.Contains(lines[i], "|") {
+			break
+		}
+
+		rows = append(rows, splitTableRow(lines[i]))
+	}
+
+	columns := len(header)
+	node := &DocxNode{Block: &TableBlock{Rows: len(rows), Columns: columns, HeaderRow: true}}
+	for _, row := range rows {
+		for column := 0; column < columns; column++ {
+			cell := &DocxNode{Block: &TableCellBlock{}}
+			if column < len(row) {
+				for _, part := range markdownLineBreak.Split(row[column], -1) {
+					cell.Children = append(cell.Children, parseMarkdownBlocks([]string{part})...)
+				}
+			}
+
+			node.Children = append(node.Children, cell)
+		}
+	}
+
+	return node, i
+}
+
+// splitTableRow 按未转义的 | 拆分表格行
+func splitTableRow(line string) []string {
+	line = strings.TrimSpace(line)
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var builder strings.Builder
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && i+1 < len(line) && line[i+1] == '|':
+			builder.WriteByte('|')
+			i++
+		case line[i] == '|':
+			cells = append(cells, strings.TrimSpace(builder.String()))
+			builder.Reset()
+		default:
+			builder.WriteByte(line[i])
+		}
+	}
+
+	return append(cells, strings.TrimSpace(builder.String()))
+}
+
+// parseParagraph 解析段落，单独成行的图片作为图片 Block，后接 === 或 --- 时为标题
+func parseParagraph(lines []string, i int) ([]*DocxNode, int) {
+	paragraph := []string{lines[i]}
+	for i++; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if trimmed != "" && strings.Trim(trimmed, "=") == "" {
+			i++
+			return []*DocxNode{{Block: &HeadingBlock{Level: 1, Elements: parseInlines(joinParagraph(paragraph))}}}, i
+		}
+
+		if trimmed != "" && strings.Trim(trimmed, "-") == "" {
+			i++
+			return []*DocxNode{{Block: &HeadingBlock{Level: 2, Elements: parseInlines(joinParagraph(paragraph))}}}, i
+		}
+
+		if isBlockStart(lines, i) {
+			break
+		}
+
+		paragraph = append(paragraph, lines[i])
+	}
+
+	var images []*DocxNode
+	for _, line := range paragraph {
+		match := markdownImage.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			images = nil
+			break
+		}
+
+		images = append(images, &DocxNode{Block: &ImageBlock{}, ImageSrc: match[2]})
+	}
+
+	if images != nil {
+		return images, i
+	}
+
+	return []*DocxNode{{Block: &TextBlock{Elements: parseInlines(joinParagraph(paragraph))}}}, i
+}
+
+// joinParagraph 合并段落中的行，行尾两个空格或反斜杠表示换行
+func joinParagraph(lines []string) string {
+	var builder strings.Builder
+	for i, line := range lines {
+		hardBreak := strings.HasSuffix(line, "  ") || strings.HasSuffix(line, "\\")
+		line = strings.TrimSpace(line)
+		if hardBreak {
+			line = strings.TrimSuffix(line, "\\")
+		}
+
+		builder.WriteString(line)
+		if i == len(lines)-1 {
+			break
+		}
+
+		if hardBreak {
+			builder.WriteString("\n")
+		} else {
+			builder.WriteString(" ")
+		}
+	}
+
+	return builder.String()
+}
+
+func countRun(text string, c byte) int {
+	n := 0
+	for n < len(text) && text[n] == c {
+		n++
+	}
+
+	return n
+}
+
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+func isWordByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+}
+
+func isPunctByte(c byte) bool {
+	return c < 0x80 && c > ' ' && !isWordByte(c)
+}
+
+// parseInlines 解析行内样式、链接、代码和公式
+func parseInlines(text string) []Inline {
+	var result []Inline
+	appendInlines(&result, text, Inline{Kind: InlineText})
+	return mergeInlines(result)
+}
+
+func appendInlines(result *[]Inline, text string, style Inline) {
+	var builder strings.Builder
+	flush := func() {
+		if builder.Len() > 0 {
+			element := style
+			element.Text = builder.String()
+			*result = append(*result, element)
+			builder.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == '\\' && i+1 < len(text) && isPunctByte(text[i+1]):
+			builder.WriteByte(text[i+1])
+			i += 2
+			continue
+		case c == '`':
+			n := countRun(text[i:], '`')
+			if end := strings.Index(text[i+n:], text[i:i+n]); end >= 0 {
+				flush()
+				element := style
+				element.Code = true
+				element.Text = trimCodeSpan(text[i+n : i+n+end])
+				*result = append(*result, element)
+				i += n + end + n
+				continue
+			}
+
+			builder.WriteString(text[i : i+n])
+			i += n
+			continue
+		case c == '!' && i+1 < len(text) && text[i+1] == '[':
+			// 行内图片写为指向图片的链接
+			if label, dest, n, ok := parseMarkdownLink(text[i+1:]); ok {
+				flush()
+				element := style
+				element.Link = dest
+				if label == "" {
+					label = dest
+				}
+				appendInlines(result, label, element)
+				i += 1 + n
+				continue
+			}
+		case c == '[':
+			if label, dest, n, ok := parseMarkdownLink(text[i:]); ok {
+				flush()
+				element := style
+				element.Link = dest
+				appendInlines(result, label, element)
+				i += n
+				continue
+			}
+		case c == '<':
+			if end := strings.IndexByte(text[i:], '>'); end > 0 {
+				link := text[i+1 : i+end]
+				if (strings.HasPrefix(link, "http://") || strings.HasPrefix(link, "https://") || strings.HasPrefix(link, "mailto:")) &&
+					!strings.ContainsAny(link, " \t") {
+					flush()
+					element := style
+					element.Link = link
+					element.Text = link
+					*result = append(*result, element)
+					i += end + 1
+					continue
+				}
+			}
+		case c == '$':
+			if end := findEquationEnd(text, i); end > 0 {
+				flush()
+				*result = append(*result, Inline{Kind: InlineEquation, Text: text[i+1 : end]})
+				i = end + 1
+				continue
+			}
+		case c == '*' || c == '_' || c == '~':
+			n := countRun(text[i:], c)
+			opens := n <= 3 && (c != '~' || n == 2) &&
+				i+n < len(text) && !isSpaceByte(text[i+n]) &&
+				(c != '_' || i == 0 || !isWordByte(text[i-1]))
+			if opens {
+				if end := findEmphasisEnd(text, i+n, text[i:i+n]); end >= 0 {
+					flush()
+					element := style
+					switch {
+					case c == '~':
+						element.Strikethrough = true
+					case n == 1:
+						element.Italic = true
+					case n == 2:
+						element.Bold = true
+					default:
+						element.Bold = true
+						element.Italic = true
+					}
+					appendInlines(result, text[i+n:end], element)
+					i = end + n
+					continue
+				}
+			}
+
+			builder.WriteString(text[i : i+n])
+			i += n
+			continue
+		}
+
+		builder.WriteByte(c)
+		i++
+	}
+
+	flush()
+}
+
+// trimCodeSpan 代码两端各有一个空格时去除
+func trimCodeSpan(code string) string {
+	if len(code) >= 2 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+		return code[1 : len(code)-1]
+	}
+
+	return code
+}
+
+// findEmphasisEnd 从 start 开始查找与 delim 配对的结束标记，跳过转义、行内代码和嵌套的其他样式
+func findEmphasisEnd(text string, start int, delim string) int {
+	for j := start; j < len(text); {
+		c := text[j]
+		switch c {
+		case '\\':
+			j += 2
+		case '`':
+			n := countRun(text[j:], '`')
+			if end := strings.Index(text[j+n:], text[j:j+n]); end >= 0 {
+				j += n + end + n
+			} else {
+				j += n
+			}
+		case '*', '_', '~':
+			n := countRun(text[j:], c)
+			closes := c == delim[0] && n == len(delim) && j > start && !isSpaceByte(text[j-1]) &&
+				(c != '_' || j+n == len(text) || !isWordByte(text[j+n]))
+			if closes {
+				return j
+			}
+
+			// 跳过嵌套样式的整段内容
+			if j+n < len(text) && !isSpaceByte(text[j+n]) {
+				if end := findEmphasisEnd(text, j+n, text[j:j+n]); end >= 0 {
+					j = end + n
+					continue
+				}
+			}
+
+			j += n
+		default:
+			j++
+		}
+	}
+
+	return -1
+}
+
+// findEquationEnd 查找 $公式$ 的结束位置，内容首尾不能为空格，结束符后不能紧跟数字
+func findEquationEnd(text string, start int) int {
+	if start+1 >= len(text) || isSpaceByte(text[start+1]) || text[start+1] == '$' {
+		return -1
+	}
+
+	for j := start + 1; j < len(text); j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '$':
+			if isSpaceByte(text[j-1]) || j+1 < len(text) && text[j+1] >= '0' && text[j+1] <= '9' {
+				return -1
+			}
+
+			return j
+		}
+	}
+
+	return -1
+}
+
+// parseMarkdownLink 解析以 [ 开头的 [text](url "title")，返回文本、地址和消耗的字节数
+func parseMarkdownLink(text string) (string, string, int, bool) {
+	depth, closing := 0, -1
+	for j := 0; j < len(text) && closing < 0; j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				closing = j
+			}
+		}
+	}
+
+	if closing < 0 || closing+1 >= len(text) || text[closing+1] != '(' {
+		return "", "", 0, false
+	}
+
+	depth, end := 0, -1
+	for j := closing + 1; j < len(text) && end < 0; j++ {
+		switch text[j] {
+		case '\\':
+			j++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				end = j
+			}
+		}
+	}
+
+	if end < 0 {
+		return "", "", 0, false
+	}
+
+	dest := strings.TrimSpace(text[closing+2 : end])
+	if strings.HasPrefix(dest, "<") {
+		if k := strings.IndexByte(dest, '>'); k > 0 {
+			dest = dest[1:k]
+		}
+	} else if k := strings.IndexAny(dest, " \t"); k >= 0 {
+		dest = dest[:k]
+	}
+
+	return text[1:closing], dest, end + 1, true
+}
+
+// mergeInlines 合并相邻且样式相同的文本
+func mergeInlines(elements []Inline) []Inline {
+	merged := make([]Inline, 0, len(elements))
+	for _, element := range elements {
+		if n := len(merged); n > 0 && element.Kind == InlineText && merged[n-1].Kind == InlineText {
+			last, current := merged[n-1], element
+			last.Text, current.Text = "", ""
+			if last == current {
+				merged[n-1].Text += element.Text
+				continue
+			}
+		}
+
+		merged = append(merged, element)
+	}
+
+	return merged
+}
@@ -8,15 +8,23 @@ import (
 
 // GetMessage 获取指定消息
 func (c *Client) GetMessage(ctx context.Context, messageId string) (*larkim.Message, error) {
-	resp, err := c.Im.Message.Get(ctx, larkim.NewGetMessageReqBuilder().MessageId(messageId).Build())
+	var resp *larkim.GetMessageResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Im.Message.Get(ctx, larkim.NewGetMessageReqBuilder().MessageId(messageId).Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetMessage")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if !resp.Success() {
-		return nil, newLarkError(resp.Code, resp.Msg, "GetMessage")
-	}
-
 	return resp.Data.Items[0], nil
 }
 
@@ -27,23 +35,28 @@ func GetMessage(ctx context.Context, messageId string) (*larkim.Message, error)
 
 // ReplyMessage 回复消息
 func (c *Client) ReplyMessage(ctx context.Context, message, messageId, messageType string, inThread bool) error {
-	resp, err := c.Im.Message.Reply(ctx,
-		larkim.NewReplyMessageReqBuilder().Body(
-			larkim.NewReplyMessageReqBodyBuilder().
-				MsgType(messageType).
-				Content(message).
-				ReplyInThread(inThread).
-				Build()).
-			MessageId(messageId).Build())
-	if err != nil {
-		return err
-	}
+	// 携带 uuid 由服务端去重，重试不会重复回复
+	uuid := newRequestUuid()
+	return c.retry(ctx, retrySafe, func() error {
+		resp, err := c.Im.Message.Reply(ctx,
+			larkim.NewReplyMessageReqBuilder().Body(
+				larkim.NewReplyMessageReqBodyBuilder().
+					MsgType(messageType).
+					Content(message).
+					ReplyInThread(inThread).
+					Uuid(uuid).
+					Build()).
+				MessageId(messageId).Build())
+		if err != nil {
+			return err
+		}
 
-	if !resp.Success() {
-		return newLarkError(resp.Code, resp.Msg, "ReplyMessage")
-	}
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "ReplyMessage")
+		}
 
-	return nil
+		return nil
+	})
 }
 
 // ReplyMessage 回复消息
@@ -102,22 +115,33 @@ func ReplyCardTemplate(ctx context.Context, inThread bool, messageId, templateId
 }
 
 func (c *Client) SendMessage(ctx context.Context, receiverIdType, message, receiveId, messageType string) (string, error) {
-	resp, err := c.Im.Message.Create(ctx,
-		larkim.NewCreateMessageReqBuilder().Body(
-			larkim.NewCreateMessageReqBodyBuilder().
-				MsgType(messageType).
-				ReceiveId(receiveId).
-				Content(message).
-				Build()).
-			ReceiveIdType(receiverIdType).Build())
+	// 携带 uuid 由服务端去重，重试不会重复发送
+	uuid := newRequestUuid()
+	var resp *larkim.CreateMessageResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Im.Message.Create(ctx,
+			larkim.NewCreateMessageReqBuilder().Body(
+				larkim.NewCreateMessageReqBodyBuilder().
+					MsgType(messageType).
+					ReceiveId(receiveId).
+					Content(message).
+					Uuid(uuid).
+					Build()).
+				ReceiveIdType(receiverIdType).Build())
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "SendMessage")
+		}
+
+		return nil
+	})
 	if err != nil {
 		return "", err
 	}
 
-	if !resp.Success() {
-		return "", newLarkError(resp.Code, resp.Msg, "SendMessage")
-	}
-
 	return *resp.Data.MessageId, nil
 }
 
@@ -126,9 +150,69 @@ func SendMessage(ctx context.Context, receiverIdType, message, receiveId, messag
 	return GlobalClient.SendMessage(ctx, receiverIdType, message, receiveId, messageType)
 }
 
+// SendMessageTo 发送消息到指定接收者
+func (c *Client) SendMessageTo(ctx context.Context, to Receiver, message, messageType string) (string, error) {
+	return c.SendMessage(ctx, to.IdType, message, to.Id, messageType)
+}
+
+// SendMessageTo 发送消息到指定接收者
+func SendMessageTo(ctx context.Context, to Receiver, message, messageType string) (string, error) {
+	return GlobalClient.SendMessageTo(ctx, to, message, messageType)
+}
+
+// SendTextTo 使用文本发送消息到指定接收者
+func (c *Client) SendTextTo(ctx context.Context, to Receiver, title string, text ...string) (string, error) {
+	content, err := buildPost(title, text)
+	if err != nil {
+		return "", err
+	}
+
+	return c.SendMessageTo(ctx, to, content, larkim.MsgTypePost)
+}
+
+// SendTextTo 使用文本发送消息到指定接收者
+func SendTextTo(ctx context.Context, to Receiver, title string, text ...string) (string, error) {
+	return GlobalClient.SendTextTo(ctx, to, title, text...)
+}
+
+// SendImageTo 使用图片发送消息到指定接收者
+func (c *Client) SendImageTo(ctx context.Context, to Receiver, imageKey string) (string, error) {
+	return c.SendMessageTo(ctx, to, NewImageContent(imageKey), larkim.MsgTypeImage)
+}
+
+// SendImageTo 使用图片发送消息到指定接收者
+func SendImageTo(ctx context.Context, to Receiver, imageKey string) (string, error) {
+	return GlobalClient.SendImageTo(ctx, to, imageKey)
+}
+
+// SendCardTo 使用卡片发送消息到指定接收者
+func (c *Client) SendCardTo(ctx context.Context, to Receiver, card string) (string, error) {
+	return c.SendMessageTo(ctx, to, card, larkim.MsgTypeInteractive)
+}
+
+// SendCardTo 使用卡片发送消息到指定接收者
+func SendCardTo(ctx context.Context, to Receiver, card string) (string, error) {
+	return GlobalClient.SendCardTo(ctx, to, card)
+}
+
+// SendCardTemplateTo 使用模板卡片发送消息到指定接收者
+func (c *Client) SendCardTemplateTo(ctx context.Context, to Receiver, templateId string, vars map[string]interface{}) (string, error) {
+	str, err := buildTemplateCard(templateId, vars)
+	if err != nil {
+		return "", err
+	}
+
+	return c.SendCardTo(ctx, to, str)
+}
+
+// SendCardTemplateTo 使用模板卡片发送消息到指定接收者
+func SendCardTemplateTo(ctx context.Context, to Receiver, templateId string, vars map[string]interface{}) (string, error) {
+	return GlobalClient.SendCardTemplateTo(ctx, to, templateId, vars)
+}
+
 // SendMessageToGroup 发送消息到群组
 func (c *Client) SendMessageToGroup(ctx context.Context, groupId, message, messageType string) (string, error) {
-	return c.SendMessage(ctx, larkim.ReceiveIdTypeChatId, message, groupId, messageType)
+	return c.SendMessageTo(ctx, ChatID(groupId), message, messageType)
 }
 
 // SendMessageToGroup 发送消息到群组
@@ -153,7 +237,7 @@ func SendTextToGroup(ctx context.Context, groupId, title string, text ...string)
 
 // SendImageToGroup 使用图片发送消息到群组
 func (c *Client) SendImageToGroup(ctx context.Context, groupId, imageKey string) (string, error) {
-	return c.SendMessage(ctx, larkim.ReceiveIdTypeChatId, NewImageContent(imageKey), groupId, larkim.MsgTypeImage)
+	return c.SendImageTo(ctx, ChatID(groupId), imageKey)
 }
 
 // SendImageToGroup 使用图片发送消息到群组
@@ -163,7 +247,7 @@ func SendImageToGroup(ctx context.Context, groupId, imageKey string) (string, er
 
 // SendCardToGroup 使用卡片发送消息到群组
 func (c *Client) SendCardToGroup(ctx context.Context, groupId, card string) (string, error) {
-	return c.SendMessage(ctx, larkim.ReceiveIdTypeChatId, card, groupId, larkim.MsgTypeInteractive)
+	return c.SendCardTo(ctx, ChatID(groupId), card)
 }
 
 // SendCardToGroup 使用卡片发送消息到群组
@@ -188,7 +272,7 @@ func SendCardTemplateToGroup(ctx context.Context, groupId, templateId string, va
 
 // SendMessageToUser 发送消息到用户
 func (c *Client) SendMessageToUser(ctx context.Context, openId, message, messageType string) (string, error) {
-	return c.SendMessage(ctx, larkim.ReceiveIdTypeOpenId, message, openId, messageType)
+	return c.SendMessageTo(ctx, OpenID(openId), message, messageType)
 }
 
 // SendMessageToUser 发送消息到用户
@@ -213,7 +297,7 @@ func SendTextToUser(ctx context.Context, openId, title string, text ...string) (
 
 // SendImageToUser 使用图片发送消息到用户
 func (c *Client) SendImageToUser(ctx context.Context, openId, imageKey string) (string, error) {
-	return c.SendMessage(ctx, larkim.ReceiveIdTypeOpenId, NewImageContent(imageKey), openId, larkim.MsgTypeImage)
+	return c.SendImageTo(ctx, OpenID(openId), imageKey)
 }
 
 // SendImageToUser 使用图片发送消息到用户
@@ -223,7 +307,7 @@ func SendImageToUser(ctx context.Context, openId, imageKey string) (string, erro
 
 // SendCardToUser 使用卡片发送消息到用户
 func (c *Client) SendCardToUser(ctx context.Context, openId, card string) (string, error) {
-	return c.SendMessage(ctx, larkim.ReceiveIdTypeOpenId, card, openId, larkim.MsgTypeInteractive)
+	return c.SendCardTo(ctx, OpenID(openId), card)
 }
 
 // SendCardToUser 使用卡片发送消息到用户
@@ -247,22 +331,24 @@ func SendCardTemplateToUser(ctx context.Context, openId, templateId string, vars
 }
 
 func (c *Client) UpdateMessage(ctx context.Context, messageId, message, messageType string) error {
-	resp, err := c.Im.Message.Update(ctx,
-		larkim.NewUpdateMessageReqBuilder().Body(
-			larkim.NewUpdateMessageReqBodyBuilder().
-				MsgType(messageType).
-				Content(message).
-				Build()).
-			MessageId(messageId).Build())
-	if err != nil {
-		return err
-	}
+	return c.retry(ctx, retrySafe, func() error {
+		resp, err := c.Im.Message.Update(ctx,
+			larkim.NewUpdateMessageReqBuilder().Body(
+				larkim.NewUpdateMessageReqBodyBuilder().
+					MsgType(messageType).
+					Content(message).
+					Build()).
+				MessageId(messageId).Build())
+		if err != nil {
+			return err
+		}
 
-	if !resp.Success() {
-		return newLarkError(resp.Code, resp.Msg, "UpdateMessage")
-	}
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UpdateMessage")
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func UpdateMessage(ctx context.Context, messageId, message, messageType string) error {
@@ -288,50 +374,66 @@ func (c *Client) UpdateCardTemplate(ctx context.Context, messageId, templateId s
 		return err
 	}
 
-	resp, err := c.Im.Message.Patch(ctx, larkim.NewPatchMessageReqBuilder().MessageId(messageId).
-		Body(larkim.NewPatchMessageReqBodyBuilder().
-			Content(content).Build()).Build())
-	if err != nil {
-		return err
-	}
+	return c.retry(ctx, retrySafe, func() error {
+		resp, err := c.Im.Message.Patch(ctx, larkim.NewPatchMessageReqBuilder().MessageId(messageId).
+			Body(larkim.NewPatchMessageReqBodyBuilder().
+				Content(content).Build()).Build())
+		if err != nil {
+			return err
+		}
 
-	if !resp.Success() {
-		return newLarkError(resp.Code, resp.Msg, "UpdateCardTemplate")
-	}
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UpdateCardTemplate")
+		}
 
-	return nil
+		return nil
+	})
 }
 
 func UpdateCardTemplate(ctx context.Context, messageId, templateId string, vars map[string]interface{}) error {
 	return GlobalClient.UpdateCardTemplate(ctx, messageId, templateId, vars)
 }
 
-func (c *Client) GetJoinedGroups(ctx context.Context) ([]*larkim.ListChat, error) {
-	groups := make([]*larkim.ListChat, 0)
-
-	pageToken := ""
-	for {
-		req := larkim.NewListChatReqBuilder().PageSize(50).PageToken(pageToken).Build()
-		resp, err := c.Im.Chat.List(ctx, req)
-		if err != nil {
-			return nil, err
+// IterJoinedGroups 分页迭代机器人所在的群组
+func (c *Client) IterJoinedGroups(options ...PageOption) *Pager[*larkim.ListChat] {
+	return newPager(func(ctx context.Context, pageToken string, pageSize int) ([]*larkim.ListChat, string, bool, error) {
+		builder := larkim.NewListChatReqBuilder().PageToken(pageToken)
+		if pageSize > 0 {
+			builder.PageSize(pageSize)
 		}
 
-		if !resp.Success() {
-			return nil, newLarkError(resp.Code, resp.Msg, "GetJoinedGroups")
-		}
+		var resp *larkim.ListChatResp
+		err := c.retry(ctx, retrySafe, func() (err error) {
+			resp, err = c.Im.Chat.List(ctx, builder.Build())
+			if err != nil {
+				return err
+			}
 
-		groups = append(groups, resp.Data.Items...)
-		if !*resp.Data.HasMore || resp.Data.PageToken == nil {
-			break
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetJoinedGroups")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, "", false, err
 		}
 
-		pageToken = *resp.Data.PageToken
-	}
+		return resp.Data.Items, derefString(resp.Data.PageToken), derefBool(resp.Data.HasMore), nil
+	}, 50, options...)
+}
 
-	return groups, nil
+// IterJoinedGroups 分页迭代机器人所在的群组
+func IterJoinedGroups(options ...PageOption) *Pager[*larkim.ListChat] {
+	return GlobalClient.IterJoinedGroups(options...)
+}
+
+// GetJoinedGroups 获取机器人所在的全部群组
+func (c *Client) GetJoinedGroups(ctx context.Context) ([]*larkim.ListChat, error) {
+	return c.IterJoinedGroups().Collect(ctx)
 }
 
+// GetJoinedGroups 获取机器人所在的全部群组
 func GetJoinedGroups(ctx context.Context) ([]*larkim.ListChat, error) {
 	return GlobalClient.GetJoinedGroups(ctx)
 }
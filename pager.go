@@ -0,0 +1,217 @@
+package larki
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrIteratorDone 迭代器已无更多数据
+var ErrIteratorDone = errors.New("larki: no more items in iterator")
+
+// pageFetcher 拉取一页数据，返回数据、下一页 token 以及是否还有更多
+type pageFetcher[T any] func(ctx context.Context, pageToken string, pageSize int) ([]T, string, bool, error)
+
+// PageOption 分页迭代器配置
+type PageOption func(*pageOptions)
+
+type pageOptions struct {
+	pageSize  int
+	pageToken string
+	limit     int
+}
+
+// WithPageSize 设置每页拉取的数量，0 表示使用接口默认值
+func WithPageSize(size int) PageOption {
+	return func(o *pageOptions) {
+		o.pageSize = size
+	}
+}
+
+// WithPageToken 从指定的 page token 开始迭代，用于断点续拉
+func WithPageToken(token string) PageOption {
+	return func(o *pageOptions) {
+		o.pageToken = token
+	}
+}
+
+// WithPageLimit 限制最多返回的条目数，0 表示不限制
+func WithPageLimit(limit int) PageOption {
+	return func(o *pageOptions) {
+		o.limit = limit
+	}
+}
+
+// Pager 惰性分页迭代器，只有在需要时才拉取下一页
+type Pager[T any] struct {
+	fetch     pageFetcher[T]
+	pageSize  int
+	pageToken string
+	limit     int
+	count     int
+	buf       []T
+	done      bool
+	err       error
+}
+
+func newPager[T any](fetch pageFetcher[T], defaultPageSize int, options ...PageOption) *Pager[T] {
+	opts := pageOptions{pageSize: defaultPageSize}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	if opts.limit > 0 && opts.limit < opts.pageSize {
+		opts.pageSize = opts.limit
+	}
+
+	return &Pager[T]{
+		fetch:     fetch,
+		pageSize:  opts.pageSize,
+		pageToken: opts.pageToken,
+		limit:     opts.limit,
+	}
+}
+
+// Next 返回下一条数据，没有更多数据时返回 ErrIteratorDone
+func (p *Pager[T]) Next(ctx context.Context) (T, error) {
+	var zero T
+	for len(p.buf) == 0 {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return zero, err
+		}
+		p.buf = page
+	}
+
+	item := p.buf[0]
+	p.buf = p.buf[1:]
+	return item, nil
+}
+
+// NextPage 返回下一页数据，没有更多数据时返回 ErrIteratorDone
+// 若之前通过 Next 读取过部分数据，则先返回当前页剩余的数据
+func (p *Pager[T]) NextPage(ctx context.Context) ([]T, error) {
+	if len(p.buf) > 0 {
+		page := p.buf
+		p.buf = nil
+		return page, nil
+	}
+
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	if p.done {
+		return nil, ErrIteratorDone
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	items, nextToken, hasMore, err := p.fetch(ctx, p.pageToken, p.pageSize)
+	if err != nil {
+		p.err = err
+		return nil, err
+	}
+
+	p.pageToken = nextToken
+	if !hasMore || nextToken == "" {
+		p.done = true
+	}
+
+	if p.limit > 0 {
+		if p.count+len(items) >= p.limit {
+			items = items[:p.limit-p.count]
+			p.done = true
+		}
+	}
+	p.count += len(items)
+
+	if len(items) == 0 {
+		if p.done {
+			return nil, ErrIteratorDone
+		}
+		return p.NextPage(ctx)
+	}
+
+	return items, nil
+}
+
+// PageToken 返回下一页的 page token，可配合 WithPageToken 断点续拉
+// 已拉取但尚未通过 Next 读取的数据不包含在内
+func (p *Pager[T]) PageToken() string {
+	if p.done {
+		return ""
+	}
+
+	return p.pageToken
+}
+
+// Done 是否已经迭代完毕
+func (p *Pager[T]) Done() bool {
+	return p.done && len(p.buf) == 0
+}
+
+// All 返回 range-func 风格的迭代函数，yield 返回 false 时提前停止
+//
+//	pager.All(ctx)(func(item T, err error) bool {
+//		...
+//		return true
+//	})
+//
+// Go 1.23 及以上版本也可以直接 range 该函数
+func (p *Pager[T]) All(ctx context.Context) func(yield func(T, error) bool) {
+	return func(yield func(T, error) bool) {
+		for {
+			item, err := p.Next(ctx)
+			if errors.Is(err, ErrIteratorDone) {
+				return
+			}
+
+			if !yield(item, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+
+// Collect 拉取剩余全部数据
+func (p *Pager[T]) Collect(ctx context.Context) ([]T, error) {
+	items := make([]T, 0)
+	for {
+		page, err := p.NextPage(ctx)
+		if errors.Is(err, ErrIteratorDone) {
+			return items, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, page...)
+	}
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func derefBool(b *bool) bool {
+	if b == nil {
+		return false
+	}
+
+	return *b
+}
+
+func derefInt(i *int) int {
+	if i == nil {
+		return 0
+	}
+
+	return *i
+}
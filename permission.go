@@ -0,0 +1,308 @@
+package larki
+
+import (
+	"context"
+	"fmt"
+
+	larkdrive "github.com/larksuite/oapi-sdk-go/v3/service/drive/v1"
+	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+)
+
+// 协作者权限
+const (
+	PermView       = "view"
+	PermEdit       = "edit"
+	PermFullAccess = "full_access"
+)
+
+// 链接分享设置
+const (
+	LinkShareClosed         = "closed"
+	LinkShareTenantReadable = "tenant_readable"
+	LinkShareTenantEditable = "tenant_editable"
+	LinkShareAnyoneReadable = "anyone_readable"
+	LinkShareAnyoneEditable = "anyone_editable"
+)
+
+// Collaborator 云文档协作者，MemberType 为 openid、openchat、opendepartmentid 等
+type Collaborator struct {
+	MemberType string
+	MemberId   string
+	// Type 协作者类型：user、chat、department
+	Type string
+}
+
+// DepartmentCollaborator 以 open_department_id 指定部门协作者
+func DepartmentCollaborator(openDepartmentId string) Collaborator {
+	return Collaborator{MemberType: "opendepartmentid", MemberId: openDepartmentId, Type: "department"}
+}
+
+// CollaboratorOf 将消息接收者转换为协作者
+func CollaboratorOf(r Receiver) (Collaborator, error) {
+	switch r.IdType {
+	case larkim.ReceiveIdTypeOpenId:
+		return Collaborator{MemberType: "openid", MemberId: r.Id, Type: "user"}, nil
+	case larkim.ReceiveIdTypeUnionId:
+		return Collaborator{MemberType: "unionid", MemberId: r.Id, Type: "user"}, nil
+	case larkim.ReceiveIdTypeUserId:
+		return Collaborator{MemberType: "userid", MemberId: r.Id, Type: "user"}, nil
+	case larkim.ReceiveIdTypeEmail:
+		return Collaborator{MemberType: "email", MemberId: r.Id, Type: "user"}, nil
+	case larkim.ReceiveIdTypeChatId:
+		return Collaborator{MemberType: "openchat", MemberId: r.Id, Type: "chat"}, nil
+	}
+
+	return Collaborator{}, fmt.Errorf("larki: unsupported receiver id type %q", r.IdType)
+}
+
+func (m Collaborator) baseMember(perm string) *larkdrive.BaseMember {
+	return larkdrive.NewBaseMemberBuilder().
+		MemberType(m.MemberType).
+		MemberId(m.MemberId).
+		Type(m.Type).
+		Perm(perm).
+		Build()
+}
+
+// AddCollaborator 为云文档添加协作者，docType 为 doc、docx、sheet、bitable、file、folder 等
+func (c *Client) AddCollaborator(ctx context.Context, token, docType string, member Collaborator, perm string, notify bool) error {
+	req := larkdrive.NewCreatePermissionMemberReqBuilder().
+		Token(token).
+		Type(docType).
+		NeedNotification(notify).
+		BaseMember(member.baseMember(perm)).
+		Build()
+
+	return c.retry(ctx, retrySafe, func() error {
+		resp, err := c.Drive.PermissionMember.Create(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "AddCollaborator")
+		}
+
+		return nil
+	})
+}
+
+// AddCollaborator 为云文档添加协作者
+func AddCollaborator(ctx context.Context, token, docType string, member Collaborator, perm string, notify bool) error {
+	return GlobalClient.AddCollaborator(ctx, token, docType, member, perm, notify)
+}
+
+// UpdateCollaborator 修改协作者的权限
+func (c *Client) UpdateCollaborator(ctx context.Context, token, docType string, member Collaborator, perm string) error {
+	req := larkdrive.NewUpdatePermissionMemberReqBuilder().
+		Token(token).
+		Type(docType).
+		MemberId(member.MemberId).
+		BaseMember(member.baseMember(perm)).
+		Build()
+
+	return c.retry(ctx, retrySafe, func() error {
+		resp, err := c.Drive.PermissionMember.Update(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UpdateCollaborator")
+		}
+
+		return nil
+	})
+}
+
+// UpdateCollaborator 修改协作者的权限
+func UpdateCollaborator(ctx context.Context, token, docType string, member Collaborator, perm string) error {
+	return GlobalClient.UpdateCollaborator(ctx, token, docType, member, perm)
+}
+
+// RemoveCollaborator 移除协作者
+func (c *Client) RemoveCollaborator(ctx context.Context, token, docType string, member Collaborator) error {
+	req := larkdrive.NewDeletePermissionMemberReqBuilder().
+		Token(token).
+		Type(docType).
+		MemberId(member.MemberId).
+		MemberType(member.MemberType).
+		Body(larkdrive.NewDeletePermissionMemberReqBodyBuilder().Type(member.Type).Build()).
+		Build()
+
+	return c.retry(ctx, retrySafe, func() error {
+		resp, err := c.Drive.PermissionMember.Delete(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "RemoveCollaborator")
+		}
+
+		return nil
+	})
+}
+
+// RemoveCollaborator 移除协作者
+func RemoveCollaborator(ctx context.Context, token, docType string, member Collaborator) error {
+	return GlobalClient.RemoveCollaborator(ctx, token, docType, member)
+}
+
+// ListCollaborators 获取云文档的全部协作者
+func (c *Client) ListCollaborators(ctx context.Context, token, docType string) ([]*larkdrive.Member, error) {
+	req := larkdrive.NewListPermissionMemberReqBuilder().
+		Token(token).
+		Type(docType).
+		Build()
+
+	var resp *larkdrive.ListPermissionMemberResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Drive.PermissionMember.List(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "ListCollaborators")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.Items, nil
+}
+
+// ListCollaborators 获取云文档的全部协作者
+func ListCollaborators(ctx context.Context, token, docType string) ([]*larkdrive.Member, error) {
+	return GlobalClient.ListCollaborators(ctx, token, docType)
+}
+
+// TransferOwner 转移云文档所有者，removeOldOwner 为 false 时原所有者保留 full_access 权限
+func (c *Client) TransferOwner(ctx context.Context, token, docType string, owner Collaborator, removeOldOwner bool) error {
+	req := larkdrive.NewTransferOwnerPermissionMemberReqBuilder().
+		Token(token).
+		Type(docType).
+		RemoveOldOwner(removeOldOwner).
+		OldOwnerPerm(PermFullAccess).
+		Owner(larkdrive.NewOwnerBuilder().
+			MemberType(owner.MemberType).
+			MemberId(owner.MemberId).
+			Build()).
+		Build()
+
+	return c.retry(ctx, retryRateLimited, func() error {
+		resp, err := c.Drive.PermissionMember.TransferOwner(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "TransferOwner")
+		}
+
+		return nil
+	})
+}
+
+// TransferOwner 转移云文档所有者
+func TransferOwner(ctx context.Context, token, docType string, owner Collaborator, removeOldOwner bool) error {
+	return GlobalClient.TransferOwner(ctx, token, docType, owner, removeOldOwner)
+}
+
+// GetPublicPermission 获取云文档的公共权限设置
+func (c *Client) GetPublicPermission(ctx context.Context, token, docType string) (*larkdrive.PermissionPublic, error) {
+	req := larkdrive.NewGetPermissionPublicReqBuilder().
+		Token(token).
+		Type(docType).
+		Build()
+
+	var resp *larkdrive.GetPermissionPublicResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Drive.PermissionPublic.Get(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetPublicPermission")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.PermissionPublic, nil
+}
+
+// GetPublicPermission 获取云文档的公共权限设置
+func GetPublicPermission(ctx context.Context, token, docType string) (*larkdrive.PermissionPublic, error) {
+	return GlobalClient.GetPublicPermission(ctx, token, docType)
+}
+
+// UpdatePublicPermission 更新云文档的公共权限设置，未设置的字段保持不变
+func (c *Client) UpdatePublicPermission(ctx context.Context, token, docType string, permission *larkdrive.PermissionPublicRequest) (*larkdrive.PermissionPublic, error) {
+	req := larkdrive.NewPatchPermissionPublicReqBuilder().
+		Token(token).
+		Type(docType).
+		PermissionPublicRequest(permission).
+		Build()
+
+	var resp *larkdrive.PatchPermissionPublicResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Drive.PermissionPublic.Patch(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UpdatePublicPermission")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.PermissionPublic, nil
+}
+
+// UpdatePublicPermission 更新云文档的公共权限设置
+func UpdatePublicPermission(ctx context.Context, token, docType string, permission *larkdrive.PermissionPublicRequest) (*larkdrive.PermissionPublic, error) {
+	return GlobalClient.UpdatePublicPermission(ctx, token, docType, permission)
+}
+
+// SetLinkShare 设置链接分享范围以及是否允许分享到组织外，linkShare 取 LinkShare* 常量
+func (c *Client) SetLinkShare(ctx context.Context, token, docType, linkShare string, externalAccess bool) error {
+	_, err := c.UpdatePublicPermission(ctx, token, docType, larkdrive.NewPermissionPublicRequestBuilder().
+		LinkShareEntity(linkShare).
+		ExternalAccess(externalAccess).
+		Build())
+	return err
+}
+
+// SetLinkShare 设置链接分享范围以及是否允许分享到组织外
+func SetLinkShare(ctx context.Context, token, docType, linkShare string, externalAccess bool) error {
+	return GlobalClient.SetLinkShare(ctx, token, docType, linkShare, externalAccess)
+}
+
+// ShareWith 将云文档分享给用户或群组并发送通知，to 与发送消息时的接收者相同
+func (c *Client) ShareWith(ctx context.Context, token, docType string, to Receiver, perm string) error {
+	member, err := CollaboratorOf(to)
+	if err != nil {
+		return err
+	}
+
+	return c.AddCollaborator(ctx, token, docType, member, perm, true)
+}
+
+// ShareWith 将云文档分享给用户或群组
+func ShareWith(ctx context.Context, token, docType string, to Receiver, perm string) error {
+	return GlobalClient.ShareWith(ctx, token, docType, to, perm)
+}
@@ -0,0 +1,138 @@
+package larki
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	larkcontact "github.com/larksuite/oapi-sdk-go/v3/service/contact/v3"
+	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
+)
+
+// Receiver 消息接收者，IdType 对应飞书的 receive_id_type
+type Receiver struct {
+	IdType string
+	Id     string
+}
+
+// OpenID 以 open_id 指定用户
+func OpenID(id string) Receiver {
+	return Receiver{IdType: larkim.ReceiveIdTypeOpenId, Id: id}
+}
+
+// UnionID 以 union_id 指定用户
+func UnionID(id string) Receiver {
+	return Receiver{IdType: larkim.ReceiveIdTypeUnionId, Id: id}
+}
+
+// UserID 以 user_id 指定用户
+func UserID(id string) Receiver {
+	return Receiver{IdType: larkim.ReceiveIdTypeUserId, Id: id}
+}
+
+// Email 以邮箱指定用户
+func Email(email string) Receiver {
+	return Receiver{IdType: larkim.ReceiveIdTypeEmail, Id: email}
+}
+
+// ChatID 以 chat_id 指定群组
+func ChatID(id string) Receiver {
+	return Receiver{IdType: larkim.ReceiveIdTypeChatId, Id: id}
+}
+
+// IsChat 是否为群组
+func (r Receiver) IsChat() bool {
+	return r.IdType == larkim.ReceiveIdTypeChatId
+}
+
+func (r Receiver) String() string {
+	return r.IdType + ":" + r.Id
+}
+
+// ReceiverFromUserId 由消息事件中的用户 ID 构造接收者，优先使用 open_id
+func ReceiverFromUserId(id *larkim.UserId) (Receiver, bool) {
+	if id == nil {
+		return Receiver{}, false
+	}
+
+	switch {
+	case id.OpenId != nil && *id.OpenId != "":
+		return OpenID(*id.OpenId), true
+	case id.UnionId != nil && *id.UnionId != "":
+		return UnionID(*id.UnionId), true
+	case id.UserId != nil && *id.UserId != "":
+		return UserID(*id.UserId), true
+	}
+
+	return Receiver{}, false
+}
+
+// ConvertReceiver 将用户接收者转换为 idType (open_id / union_id / user_id) 类型，结果经由通讯录缓存
+func (c *Client) ConvertReceiver(ctx context.Context, r Receiver, idType string) (Receiver, error) {
+	if r.IdType == idType {
+		return r, nil
+	}
+
+	if r.IsChat() {
+		return Receiver{}, fmt.Errorf("larki: cannot convert chat receiver %s to %s", r.Id, idType)
+	}
+
+	if r.IdType == larkim.ReceiveIdTypeEmail {
+		ids, err := c.BatchGetUserIds(ctx, idType, []string{r.Id}, nil)
+		if err != nil {
+			return Receiver{}, err
+		}
+
+		id, ok := ids[r.Id]
+		if !ok {
+			return Receiver{}, newNotFoundError("user not found: "+r.Id, "ConvertReceiver")
+		}
+
+		return Receiver{IdType: idType, Id: id}, nil
+	}
+
+	user, err := c.GetUser(ctx, r.Id, r.IdType)
+	if err != nil {
+		return Receiver{}, err
+	}
+
+	var id *string
+	switch idType {
+	case larkcontact.UserIdTypeOpenId:
+		id = user.OpenId
+	case larkcontact.UserIdTypeUnionId:
+		id = user.UnionId
+	case larkcontact.UserIdTypeUserId:
+		id = user.UserId
+	case larkim.ReceiveIdTypeEmail:
+		id = user.Email
+	default:
+		return Receiver{}, fmt.Errorf("larki: unsupported id type %s", idType)
+	}
+
+	if id == nil || *id == "" {
+		return Receiver{}, errors.New("larki: " + idType + " not visible for " + r.String())
+	}
+
+	return Receiver{IdType: idType, Id: *id}, nil
+}
+
+// ConvertReceiver 转换用户接收者的 ID 类型
+func ConvertReceiver(ctx context.Context, r Receiver, idType string) (Receiver, error) {
+	return GlobalClient.ConvertReceiver(ctx, r, idType)
+}
+
+// ConvertUserId 在 open_id / union_id / user_id / email 之间转换用户 ID
+func (c *Client) ConvertUserId(ctx context.Context, id, fromType, toType string) (string, error) {
+	r, err := c.ConvertReceiver(ctx, Receiver{IdType: fromType, Id: id}, toType)
+	if err != nil {
+		return "", err
+	}
+
+	return r.Id, nil
+}
+
+// ConvertUserId 在 open_id / union_id / user_id / email 之间转换用户 ID
+func ConvertUserId(ctx context.Context, id, fromType, toType string) (string, error) {
+	return GlobalClient.ConvertUserId(ctx, id, fromType, toType)
+}
@@ -0,0 +1,199 @@
+package larki
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	mrand "math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy 接口调用的重试策略
+type RetryPolicy struct {
+	// MaxAttempts 最多尝试次数（包含首次调用），<= 1 表示不重试
+	MaxAttempts int
+	// BaseDelay 首次重试前的等待时间，之后按指数增长
+	BaseDelay time.Duration
+	// MaxDelay 单次等待的上限
+	MaxDelay time.Duration
+	// Jitter 随机抖动比例，取值 [0, 1]
+	Jitter float64
+}
+
+// DefaultRetryPolicy 默认重试策略
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    30 * time.Second,
+	Jitter:      0.2,
+}
+
+// NoRetryPolicy 不重试
+var NoRetryPolicy = RetryPolicy{MaxAttempts: 1}
+
+// WithRetryPolicy 设置客户端的重试策略
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(client *Client) {
+		client.retryPolicy = policy
+	}
+}
+
+// retryMode 接口调用可被重试的程度
+type retryMode int
+
+const (
+	// retryNever 请求体无法重放，不重试
+	retryNever retryMode = iota
+	// retryRateLimited 非幂等操作，仅在被限流（请求未被处理）时重试
+	retryRateLimited
+	// retrySafe 幂等操作，限流、服务端错误及网络错误均重试
+	retrySafe
+)
+
+// rateLimitResetHeader 飞书网关返回的限流重置时间（秒）
+const rateLimitResetHeader = "x-ogw-ratelimit-reset"
+
+// parseRetryAfter 解析限流重置时间
+func parseRetryAfter(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+
+	reset, err := strconv.ParseInt(header.Get(rateLimitResetHeader), 10, 64)
+	if err != nil || reset <= 0 {
+		return 0
+	}
+
+	return time.Duration(reset) * time.Second
+}
+
+// retry 按客户端重试策略执行 fn
+func (c *Client) retry(ctx context.Context, mode retryMode, fn func() error) error {
+	policy := c.retryPolicy
+	attempts := policy.MaxAttempts
+	if attempts < 1 || mode == retryNever {
+		attempts = 1
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			if sleepErr := sleepCtx(ctx, policy.delay(attempt, err)); sleepErr != nil {
+				return sleepErr
+			}
+		}
+
+		err = fn()
+		if err == nil || !shouldRetry(ctx, mode, err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// shouldRetry 判断错误是否可以重试
+func shouldRetry(ctx context.Context, mode retryMode, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	if IsRateLimited(err) {
+		return true
+	}
+
+	if mode != retrySafe {
+		return false
+	}
+
+	var larkErr *LarkError
+	if errors.As(err, &larkErr) {
+		return larkErr.HTTPStatus >= http.StatusInternalServerError
+	}
+
+	// 网络错误等
+	return true
+}
+
+// delay 计算第 attempt 次重试前的等待时间
+func (p RetryPolicy) delay(attempt int, err error) time.Duration {
+	var larkErr *LarkError
+	if errors.As(err, &larkErr) && larkErr.RetryAfter > 0 {
+		return larkErr.RetryAfter + p.jitter(time.Second)
+	}
+
+	d := time.Duration(float64(p.BaseDelay) * math.Pow(2, float64(attempt-1)))
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	return d - p.jitter(d)
+}
+
+func (p RetryPolicy) jitter(d time.Duration) time.Duration {
+	if p.Jitter <= 0 || d <= 0 {
+		return 0
+	}
+
+	return time.Duration(mrand.Float64() * p.Jitter * float64(d))
+}
+
+// sleepCtx 可被 ctx 取消的等待
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rewinder 返回将 reader 复位到当前位置的函数，以便重试时重放请求体
+// reader 不支持 Seek 时返回 nil
+func rewinder(reader io.Reader) func() error {
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		return nil
+	}
+
+	offset, err := seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil
+	}
+
+	return func() error {
+		_, err := seeker.Seek(offset, io.SeekStart)
+		return err
+	}
+}
+
+// bodyRetryMode 根据请求体是否可重放决定重试程度
+func bodyRetryMode(rewind func() error, mode retryMode) retryMode {
+	if rewind == nil {
+		return retryNever
+	}
+
+	return mode
+}
+
+// newRequestUuid 生成请求去重用的 uuid，使消息发送可以安全重试
+func newRequestUuid() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,521 @@
+package larki
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	larkdrive "github.com/larksuite/oapi-sdk-go/v3/service/drive/v1"
+)
+
+// 云空间文件类型
+const (
+	DriveTypeFile   = "file"
+	DriveTypeFolder = "folder"
+)
+
+// SyncActionKind 同步操作类型
+type SyncActionKind string
+
+const (
+	SyncMkdir  SyncActionKind = "mkdir"
+	SyncUpload SyncActionKind = "upload"
+	SyncUpdate SyncActionKind = "update"
+	SyncDelete SyncActionKind = "delete"
+)
+
+// SyncAction 同步计划中的一步操作
+type SyncAction struct {
+	Kind SyncActionKind
+	// Path 相对同步根目录的路径，以 / 分隔
+	Path string
+	// Token 云空间中已存在的文件或文件夹，上传新文件时为空
+	Token string
+	// Type 云空间中已存在的文件类型
+	Type string
+	Size int64
+}
+
+// SyncResult 同步结果，DryRun 时只包含计划而不执行
+type SyncResult struct {
+	Actions []SyncAction
+	// Unchanged 内容未变化而跳过的文件数
+	Unchanged int
+	// Skipped 无法同步的本地文件，例如空文件
+	Skipped []string
+}
+
+// SyncOption 同步配置
+type SyncOption func(*syncOptions)
+
+type syncOptions struct {
+	dryRun    bool
+	delete    bool
+	excludes  []string
+	statePath string
+	upload    []UploadOption
+}
+
+// WithSyncDryRun 只计算同步计划，不修改云空间
+func WithSyncDryRun() SyncOption {
+	return func(o *syncOptions) {
+		o.dryRun = true
+	}
+}
+
+// WithSyncDelete 删除云空间中本地不存在的文件和文件夹
+func WithSyncDelete() SyncOption {
+	return func(o *syncOptions) {
+		o.delete = true
+	}
+}
+
+// WithSyncExclude 排除匹配的路径，规则同 path.Match，同时匹配相对路径和文件名
+func WithSyncExclude(patterns ...string) SyncOption {
+	return func(o *syncOptions) {
+		o.excludes = append(o.excludes, patterns...)
+	}
+}
+
+// WithSyncState 在 path 记录每个文件上次同步时的大小、修改时间和哈希
+// 云空间不返回文件大小，没有记录时只能按修改时间判断文件是否变化
+func WithSyncState(path string) SyncOption {
+	return func(o *syncOptions) {
+		o.statePath = path
+	}
+}
+
+// WithSyncUploadOptions 设置上传文件时的配置
+func WithSyncUploadOptions(options ...UploadOption) SyncOption {
+	return func(o *syncOptions) {
+		o.upload = append(o.upload, options...)
+	}
+}
+
+func (o *syncOptions) excluded(rel string) bool {
+	return matchExclude(o.excludes, rel)
+}
+
+// matchExclude 判断相对路径或文件名是否匹配任一规则
+func matchExclude(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+
+		if ok, _ := path.Match(pattern, path.Base(rel)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// syncEntry 文件上次同步时的状态
+type syncEntry struct {
+	Token   string `json:"token"`
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mod_time"`
+	Hash    string `json:"hash"`
+}
+
+type localEntry struct {
+	dir     bool
+	size    int64
+	modTime time.Time
+}
+
+type remoteEntry struct {
+	token    string
+	fileType string
+	modified time.Time
+}
+
+// SyncDriveFolder 将本地目录同步到云空间文件夹，使两者的文件树一致
+// 本地新增的文件和文件夹会被上传或创建，变化的文件会上传新版本并删除旧文件
+// 只有设置 WithSyncDelete 时才会删除云空间中多余的文件，云文档等非文件类型不受影响
+func (c *Client) SyncDriveFolder(ctx context.Context, localDir, folderToken string, options ...SyncOption) (*SyncResult, error) {
+	opts := &syncOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+
+	state, err := loadSyncState(opts.statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := scanLocalDir(localDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	remote, err := c.scanDriveFolder(ctx, folderToken, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SyncResult{}
+	for _, rel := range sortedKeys(local) {
+		entry := local[rel]
+		r := remote[rel]
+
+		if entry.dir {
+			if r != nil && r.fileType == DriveTypeFolder {
+				continue
+			}
+
+			if r != nil {
+				result.Actions = append(result.Actions, SyncAction{Kind: SyncDelete, Path: rel, Token: r.token, Type: r.fileType})
+			}
+
+			result.Actions = append(result.Actions, SyncAction{Kind: SyncMkdir, Path: rel})
+			continue
+		}
+
+		if entry.size == 0 {
+			result.Skipped = append(result.Skipped, rel)
+			continue
+		}
+
+		switch {
+		case r == nil:
+			result.Actions = append(result.Actions, SyncAction{Kind: SyncUpload, Path: rel, Size: entry.size})
+		case r.fileType != DriveTypeFile:
+			result.Actions = append(result.Actions,
+				SyncAction{Kind: SyncDelete, Path: rel, Token: r.token, Type: r.fileType},
+				SyncAction{Kind: SyncUpload, Path: rel, Size: entry.size})
+		default:
+			changed, err := syncChanged(filepath.Join(localDir, filepath.FromSlash(rel)), entry, r, state, rel)
+			if err != nil {
+				return nil, err
+			}
+
+			if !changed {
+				result.Unchanged++
+				continue
+			}
+
+			result.Actions = append(result.Actions, SyncAction{Kind: SyncUpdate, Path: rel, Token: r.token, Type: r.fileType, Size: entry.size})
+		}
+	}
+
+	if opts.delete {
+		for _, rel := range sortedKeys(remote) {
+			if _, ok := local[rel]; ok {
+				continue
+			}
+
+			// 父文件夹被删除时无需单独删除
+			if parent := path.Dir(rel); parent != "." {
+				if p, ok := local[parent]; !ok || !p.dir {
+					continue
+				}
+			}
+
+			r := remote[rel]
+			result.Actions = append(result.Actions, SyncAction{Kind: SyncDelete, Path: rel, Token: r.token, Type: r.fileType})
+		}
+	}
+
+	if opts.dryRun {
+		return result, nil
+	}
+
+	folders := map[string]string{"": folderToken}
+	for rel, r := range remote {
+		if r.fileType == DriveTypeFolder {
+			folders[rel] = r.token
+		}
+	}
+
+	for _, action := range result.Actions {
+		if err = c.applySyncAction(ctx, localDir, action, folders, state, opts); err != nil {
+			// 已完成的操作仍需记录，下次同步时不会重复上传
+			_ = saveSyncState(opts.statePath, state)
+			return result, err
+		}
+	}
+
+	for rel := range state {
+		if _, ok := local[rel]; !ok {
+			delete(state, rel)
+		}
+	}
+
+	return result, saveSyncState(opts.statePath, state)
+}
+
+// SyncDriveFolder 将本地目录同步到云空间文件夹
+func SyncDriveFolder(ctx context.Context, localDir, folderToken string, options ...SyncOption) (*SyncResult, error) {
+	return GlobalClient.SyncDriveFolder(ctx, localDir, folderToken, options...)
+}
+
+func (c *Client) applySyncAction(ctx context.Context, localDir string, action SyncAction, folders map[string]string, state map[string]*syncEntry, opts *syncOptions) error {
+	parent := path.Dir(action.Path)
+	if parent == "." {
+		parent = ""
+	}
+
+	switch action.Kind {
+	case SyncMkdir:
+		token, err := c.CreateDriveFolder(ctx, path.Base(action.Path), folders[parent])
+		if err != nil {
+			return err
+		}
+
+		folders[action.Path] = token
+	case SyncDelete:
+		if err := c.DeleteDriveFile(ctx, action.Token, action.Type); err != nil && !IsNotFound(err) {
+			return err
+		}
+
+		delete(state, action.Path)
+	case SyncUpload, SyncUpdate:
+		entry, err := c.syncUpload(ctx, filepath.Join(localDir, filepath.FromSlash(action.Path)), path.Base(action.Path), folders[parent], opts)
+		if err != nil {
+			return err
+		}
+
+		state[action.Path] = entry
+		if action.Kind == SyncUpdate {
+			if err = c.DeleteDriveFile(ctx, action.Token, action.Type); err != nil && !IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// syncUpload 上传本地文件，同时计算内容哈希
+func (c *Client) syncUpload(ctx context.Context, localPath, name, folderToken string, opts *syncOptions) (*syncEntry, error) {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := fileHash(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	token, err := c.UploadFile(ctx, name, ParentTypeExplorer, folderToken, int(info.Size()), file, opts.upload...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &syncEntry{
+		Token:   token,
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Hash:    hash,
+	}, nil
+}
+
+// syncChanged 判断本地文件相对云空间是否有变化
+func syncChanged(localPath string, local *localEntry, remote *remoteEntry, state map[string]*syncEntry, rel string) (bool, error) {
+	entry, ok := state[rel]
+	if !ok || entry.Token != remote.token {
+		return local.modTime.After(remote.modified), nil
+	}
+
+	if entry.Size != local.size {
+		return true, nil
+	}
+
+	if entry.ModTime == local.modTime.UnixNano() {
+		return false, nil
+	}
+
+	// 修改时间变化但大小相同，以内容哈希为准
+	file, err := os.Open(localPath)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	hash, err := fileHash(file)
+	if err != nil {
+		return false, err
+	}
+
+	if hash != entry.Hash {
+		return true, nil
+	}
+
+	entry.ModTime = local.modTime.UnixNano()
+	return false, nil
+}
+
+func scanLocalDir(localDir string, opts *syncOptions) (map[string]*localEntry, error) {
+	entries := make(map[string]*localEntry)
+	err := filepath.WalkDir(localDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(localDir, p)
+		if err != nil {
+			return err
+		}
+
+		if rel == "." {
+			return nil
+		}
+
+		rel = filepath.ToSlash(rel)
+		if opts.excluded(rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if d.IsDir() {
+			entries[rel] = &localEntry{dir: true}
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		entries[rel] = &localEntry{size: info.Size(), modTime: info.ModTime()}
+		return nil
+	})
+
+	return entries, err
+}
+
+// scanDriveFolder 递归列出云空间文件夹下的文件和文件夹
+func (c *Client) scanDriveFolder(ctx context.Context, folderToken string, opts *syncOptions) (map[string]*remoteEntry, error) {
+	entries := make(map[string]*remoteEntry)
+	err := c.WalkDrive(ctx, folderToken, func(p string, file *larkdrive.File) error {
+		fileType := derefString(file.Type)
+		if fileType != DriveTypeFile && fileType != DriveTypeFolder {
+			return nil
+		}
+
+		rel := strings.TrimPrefix(p, "/")
+		if opts.excluded(rel) {
+			return ErrSkipFolder
+		}
+
+		// 同名文件只保留第一个
+		if _, ok := entries[rel]; ok {
+			return ErrSkipFolder
+		}
+
+		entries[rel] = &remoteEntry{
+			token:    derefString(file.Token),
+			fileType: fileType,
+			modified: driveFileTime(file.ModifiedTime),
+		}
+
+		return nil
+	}, WithWalkConcurrency(4))
+
+	return entries, err
+}
+
+// driveFileTime 解析云空间返回的秒级时间戳
+func driveFileTime(value *string) time.Time {
+	seconds, err := strconv.ParseInt(derefString(value), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return time.Unix(seconds, 0)
+}
+
+func fileHash(reader io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, reader); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func loadSyncState(statePath string) (map[string]*syncEntry, error) {
+	state := make(map[string]*syncEntry)
+	if statePath == "" {
+		return state, nil
+	}
+
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = sonic.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func saveSyncState(statePath string, state map[string]*syncEntry) error {
+	if statePath == "" {
+		return nil
+	}
+
+	data, err := sonic.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	tmp := statePath + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, statePath)
+}
+
+// sortedKeys 按路径排序，保证父目录先于子项处理
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return strings.Count(keys[i], "/") < strings.Count(keys[j], "/") ||
+			strings.Count(keys[i], "/") == strings.Count(keys[j], "/") && keys[i] < keys[j]
+	})
+
+	return keys
+}
@@ -0,0 +1,125 @@
+package larki
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTaskTimeout 异步任务在截止时间前没有结束
+var ErrTaskTimeout = errors.New("larki: async task did not finish before deadline")
+
+// TaskError 异步任务执行失败，Status 为任务返回的状态码
+type TaskError struct {
+	Op     string
+	TaskId string
+	Status int
+	Msg    string
+}
+
+func (e *TaskError) Error() string {
+	return fmt.Sprintf("lark %s task %s failed, status: %d, msg: %s", e.Op, e.TaskId, e.Status, e.Msg)
+}
+
+// PollOption 轮询配置
+type PollOption func(*pollOptions)
+
+type pollOptions struct {
+	interval    time.Duration
+	maxInterval time.Duration
+	timeout     time.Duration
+	callbacks   []func(status interface{})
+}
+
+const (
+	// DefaultPollTimeout 默认的轮询截止时间
+	DefaultPollTimeout = 10 * time.Minute
+	// MinPollInterval 轮询间隔的下限
+	MinPollInterval = 100 * time.Millisecond
+)
+
+// WithPollInterval 设置首次轮询间隔和最大间隔，间隔按指数增长
+// 小于 MinPollInterval 的间隔按 MinPollInterval 处理，maxInterval 不小于 interval
+func WithPollInterval(interval, maxInterval time.Duration) PollOption {
+	return func(o *pollOptions) {
+		if interval < MinPollInterval {
+			interval = MinPollInterval
+		}
+
+		if maxInterval < interval {
+			maxInterval = interval
+		}
+
+		o.interval = interval
+		o.maxInterval = maxInterval
+	}
+}
+
+// WithPollTimeout 设置轮询截止时间，超过时返回 ErrTaskTimeout，<= 0 时只受 ctx 控制
+func WithPollTimeout(timeout time.Duration) PollOption {
+	return func(o *pollOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithPollCallback 每次查询到任务状态时回调，status 为对应查询接口的返回值
+func WithPollCallback(fn func(status interface{})) PollOption {
+	return func(o *pollOptions) {
+		o.callbacks = append(o.callbacks, fn)
+	}
+}
+
+// PollTask 轮询异步任务直到结束，check 返回 done 为 true 时结束，返回 error 时立即停止
+// 任务失败时 check 应返回 *TaskError
+func PollTask[T any](ctx context.Context, check func(ctx context.Context) (T, bool, error), options ...PollOption) (T, error) {
+	opts := &pollOptions{
+		interval:    time.Second,
+		maxInterval: 10 * time.Second,
+		timeout:     DefaultPollTimeout,
+	}
+	for _, option := range options {
+		option(opts)
+	}
+
+	var deadline time.Time
+	if opts.timeout > 0 {
+		deadline = time.Now().Add(opts.timeout)
+	}
+
+	interval := opts.interval
+	for {
+		status, done, err := check(ctx)
+		if err != nil {
+			return status, err
+		}
+
+		for _, callback := range opts.callbacks {
+			callback(status)
+		}
+
+		if done {
+			return status, nil
+		}
+
+		wait := interval
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return status, ErrTaskTimeout
+			}
+
+			if wait > remaining {
+				wait = remaining
+			}
+		}
+
+		if err = sleepCtx(ctx, wait); err != nil {
+			return status, err
+		}
+
+		if interval *= 2; interval > opts.maxInterval {
+			interval = opts.maxInterval
+		}
+	}
+}
@@ -3,12 +3,16 @@ package larki
 import (
 	"context"
 	"io"
+	"sync"
+	"time"
 
 	larkevent "github.com/larksuite/oapi-sdk-go/v3/event"
 	larkapplication "github.com/larksuite/oapi-sdk-go/v3/service/application/v6"
 
 	lark "github.com/larksuite/oapi-sdk-go/v3"
+	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
 	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher"
+	larkcontact "github.com/larksuite/oapi-sdk-go/v3/service/contact/v3"
 	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
 )
 
@@ -19,6 +23,12 @@ type Client struct {
 	EventDispatcher *dispatcher.EventDispatcher
 	MessageClient
 	ImageClient
+
+	contactCache    larkcore.Cache
+	contactCacheTTL time.Duration
+	retryPolicy     RetryPolicy
+	limiters        *limiterSet
+	limitersOnce    sync.Once
 }
 
 type ClientOption func(*Client)
@@ -50,6 +60,15 @@ type MessageClient interface {
 	SendCardTemplateToUser(ctx context.Context, openId, templateId string, vars map[string]interface{}) (string, error)
 }
 
+// ReceiverMessageClient 按 Receiver 发送消息
+type ReceiverMessageClient interface {
+	SendMessageTo(ctx context.Context, to Receiver, message, messageType string) (string, error)
+	SendTextTo(ctx context.Context, to Receiver, title string, text ...string) (string, error)
+	SendImageTo(ctx context.Context, to Receiver, imageKey string) (string, error)
+	SendCardTo(ctx context.Context, to Receiver, card string) (string, error)
+	SendCardTemplateTo(ctx context.Context, to Receiver, templateId string, vars map[string]interface{}) (string, error)
+}
+
 type ImageClient interface {
 	GetImage(ctx context.Context, messageId, imageKey string) (io.Reader, error)
 	UploadImage(ctx context.Context, reader io.Reader) (string, error)
@@ -84,6 +103,12 @@ type CustomizedEvent struct {
 	*larkevent.EventReq
 }
 
+// DepartmentNode 部门树节点
+type DepartmentNode struct {
+	*larkcontact.Department
+	Children []*DepartmentNode
+}
+
 type botInfoResp struct {
 	Code int     `json:"code"`
 	Msg  string  `json:"msg"`
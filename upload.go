@@ -0,0 +1,394 @@
+package larki
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// UploadProgress 分片上传进度
+type UploadProgress struct {
+	BytesDone   int64
+	TotalBytes  int64
+	BlocksDone  int
+	TotalBlocks int
+}
+
+// UploadOption 分片上传配置
+type UploadOption func(*uploadOptions)
+
+type uploadOptions struct {
+	concurrency    int
+	progress       []func(UploadProgress)
+	bytesPerSecond int64
+
+	checkpointStore CheckpointStore
+	checkpointKey   string
+
+	extra string
+}
+
+// WithUploadConcurrency 设置并发上传的分片数，默认为 1
+func WithUploadConcurrency(n int) UploadOption {
+	return func(o *uploadOptions) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// WithUploadProgress 每完成一个分片回调一次上传进度，回调不会并发执行
+func WithUploadProgress(fn func(UploadProgress)) UploadOption {
+	return func(o *uploadOptions) {
+		o.progress = append(o.progress, fn)
+	}
+}
+
+// WithUploadProgressChan 每完成一个分片向 ch 发送一次上传进度，ch 已满时丢弃该次进度
+func WithUploadProgressChan(ch chan<- UploadProgress) UploadOption {
+	return WithUploadProgress(func(p UploadProgress) {
+		select {
+		case ch <- p:
+		default:
+		}
+	})
+}
+
+// WithUploadRateLimit 限制上传吞吐量（字节/秒），0 表示不限制
+func WithUploadRateLimit(bytesPerSecond int64) UploadOption {
+	return func(o *uploadOptions) {
+		o.bytesPerSecond = bytesPerSecond
+	}
+}
+
+// WithUploadCheckpoint 将上传会话和已完成的分片保存到 store，失败后可通过 ResumeUpload 继续上传
+func WithUploadCheckpoint(store CheckpointStore, key string) UploadOption {
+	return func(o *uploadOptions) {
+		o.checkpointStore = store
+		o.checkpointKey = key
+	}
+}
+
+func newUploadOptions(options ...UploadOption) *uploadOptions {
+	opts := &uploadOptions{concurrency: 1}
+	for _, option := range options {
+		option(opts)
+	}
+
+	return opts
+}
+
+// multipartApi 云空间文件和素材分片上传的三个阶段
+type multipartApi struct {
+	prepare func(ctx context.Context) (*UploadCheckpoint, error)
+	part    func(ctx context.Context, uploadId string, seq int, block []byte, checksum string) error
+	finish  func(ctx context.Context, uploadId string, blockNum int) (string, error)
+}
+
+// multipartApiFor 根据断点记录的类型构造分片上传接口
+func (c *Client) multipartApiFor(checkpoint *UploadCheckpoint) (multipartApi, error) {
+	switch checkpoint.Kind {
+	case uploadKindFile:
+		return c.docFileMultipartApi(checkpoint.Name, checkpoint.ParentType, checkpoint.ParentNode, checkpoint.Size), nil
+	case uploadKindMedia:
+		return c.docMediaMultipartApi(checkpoint.Name, checkpoint.ParentType, checkpoint.ParentNode, checkpoint.Extra, checkpoint.Size), nil
+	}
+
+	return multipartApi{}, errors.New("larki: unknown upload kind " + checkpoint.Kind)
+}
+
+// uploadBlock 待上传的分片，data 为空时由 worker 从 ReaderAt 读取
+type uploadBlock struct {
+	seq  int
+	data []byte
+}
+
+// prepareCheckpoint 发起分片上传并保存断点
+func (c *Client) prepareCheckpoint(ctx context.Context, api multipartApi, opts *uploadOptions) (*UploadCheckpoint, error) {
+	checkpoint, err := api.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	checkpoint.Key = opts.checkpointKey
+	checkpoint.Checksums = make(map[int]string, checkpoint.BlockNum)
+	if opts.checkpointStore != nil {
+		checkpoint.UpdatedAt = time.Now()
+		if err = opts.checkpointStore.Save(ctx, checkpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	return checkpoint, nil
+}
+
+// finishCheckpoint 完成分片上传并删除断点
+// 上传已在服务端完成，删除断点只是清理，失败时忽略以免丢失 fileToken
+func (c *Client) finishCheckpoint(ctx context.Context, api multipartApi, checkpoint *UploadCheckpoint, opts *uploadOptions) (string, error) {
+	fileToken, err := api.finish(ctx, checkpoint.UploadId, checkpoint.BlockNum)
+	if err != nil {
+		return "", err
+	}
+
+	if opts.checkpointStore != nil {
+		_ = opts.checkpointStore.Delete(ctx, checkpoint.Key)
+	}
+
+	return fileToken, nil
+}
+
+// uploadMultiPart 从顺序 reader 分片上传，读取仍是顺序的，上传可以并发
+func (c *Client) uploadMultiPart(ctx context.Context, api multipartApi, reader io.Reader, options ...UploadOption) (string, error) {
+	opts := newUploadOptions(options...)
+	checkpoint, err := c.prepareCheckpoint(ctx, api, opts)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.uploadBlocks(ctx, api, checkpoint, opts, func(ctx context.Context, blocks chan<- uploadBlock) error {
+		for seq := 0; seq < checkpoint.BlockNum; seq++ {
+			_, blockSize := checkpoint.blockRange(seq)
+			data := make([]byte, blockSize)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				if err == io.EOF || err == io.ErrUnexpectedEOF {
+					return ErrSizeMismatch
+				}
+				return err
+			}
+
+			select {
+			case blocks <- uploadBlock{seq: seq, data: data}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		// reader 中剩余的数据说明声明的 size 偏小
+		var extra [1]byte
+		if n, _ := reader.Read(extra[:]); n > 0 {
+			return ErrSizeMismatch
+		}
+
+		return nil
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return c.finishCheckpoint(ctx, api, checkpoint, opts)
+}
+
+// uploadMultiPartAt 从 ReaderAt 分片上传，读取和上传均可并发
+func (c *Client) uploadMultiPartAt(ctx context.Context, api multipartApi, reader io.ReaderAt, options ...UploadOption) (string, error) {
+	opts := newUploadOptions(options...)
+	checkpoint, err := c.prepareCheckpoint(ctx, api, opts)
+	if err != nil {
+		return "", err
+	}
+
+	return c.uploadCheckpointAt(ctx, api, checkpoint, reader, opts)
+}
+
+// uploadCheckpointAt 从 ReaderAt 上传断点中尚未完成的分片并完成上传
+func (c *Client) uploadCheckpointAt(ctx context.Context, api multipartApi, checkpoint *UploadCheckpoint, reader io.ReaderAt, opts *uploadOptions) (string, error) {
+	// worker 会并发写入 checkpoint.Checksums，先确定待上传的分片
+	pending := make([]int, 0, checkpoint.BlockNum)
+	for seq := 0; seq < checkpoint.BlockNum; seq++ {
+		if !checkpoint.Completed(seq) {
+			pending = append(pending, seq)
+		}
+	}
+
+	err := c.uploadBlocks(ctx, api, checkpoint, opts, func(ctx context.Context, blocks chan<- uploadBlock) error {
+		for _, seq := range pending {
+			select {
+			case blocks <- uploadBlock{seq: seq}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		return nil
+	}, reader)
+	if err != nil {
+		return "", err
+	}
+
+	return c.finishCheckpoint(ctx, api, checkpoint, opts)
+}
+
+// ResumeUpload 从断点继续分片上传，reader 必须与首次上传的数据一致
+// 已确认的分片会重新计算校验和，不一致时返回 ErrCheckpointMismatch
+func (c *Client) ResumeUpload(ctx context.Context, store CheckpointStore, key string, reader io.ReaderAt, options ...UploadOption) (string, error) {
+	checkpoint, err := store.Load(ctx, key)
+	if err != nil {
+		return "", err
+	}
+
+	api, err := c.multipartApiFor(checkpoint)
+	if err != nil {
+		return "", err
+	}
+
+	for seq, checksum := range checkpoint.Checksums {
+		offset, blockSize := checkpoint.blockRange(seq)
+		block := make([]byte, blockSize)
+		if n, err := reader.ReadAt(block, offset); n < blockSize {
+			if err == nil || err == io.EOF {
+				err = ErrCheckpointMismatch
+			}
+			return "", err
+		}
+
+		if blockChecksum(block) != checksum {
+			return "", ErrCheckpointMismatch
+		}
+	}
+
+	opts := newUploadOptions(append(options, WithUploadCheckpoint(store, key))...)
+	return c.uploadCheckpointAt(ctx, api, checkpoint, reader, opts)
+}
+
+// ResumeUpload 从断点继续分片上传
+func ResumeUpload(ctx context.Context, store CheckpointStore, key string, reader io.ReaderAt, options ...UploadOption) (string, error) {
+	return GlobalClient.ResumeUpload(ctx, store, key, reader, options...)
+}
+
+// uploadBlocks 由 produce 产生分片，opts.concurrency 个 worker 并发上传，任一分片失败即取消全部
+func (c *Client) uploadBlocks(ctx context.Context, api multipartApi, checkpoint *UploadCheckpoint, opts *uploadOptions,
+	produce func(ctx context.Context, blocks chan<- uploadBlock) error, readerAt io.ReaderAt,
+) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		once     sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	progress := newUploadProgressTracker(checkpoint, opts)
+	throttle := newByteLimiter(opts.bytesPerSecond)
+	blocks := make(chan uploadBlock)
+
+	var wg sync.WaitGroup
+	for i := 0; i < opts.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for block := range blocks {
+				if block.data == nil {
+					offset, blockSize := checkpoint.blockRange(block.seq)
+					block.data = make([]byte, blockSize)
+					if n, err := readerAt.ReadAt(block.data, offset); n < blockSize {
+						if err == nil || err == io.EOF {
+							err = io.ErrUnexpectedEOF
+						}
+						fail(err)
+						continue
+					}
+				}
+
+				if err := throttle.waitN(ctx, len(block.data)); err != nil {
+					fail(err)
+					continue
+				}
+
+				checksum := blockChecksum(block.data)
+				if err := api.part(ctx, checkpoint.UploadId, block.seq, block.data, checksum); err != nil {
+					fail(err)
+					continue
+				}
+
+				if err := progress.done(ctx, block.seq, len(block.data), checksum); err != nil {
+					fail(err)
+				}
+			}
+		}()
+	}
+
+	if err := produce(ctx, blocks); err != nil {
+		fail(err)
+	}
+	close(blocks)
+	wg.Wait()
+
+	return firstErr
+}
+
+// uploadProgressTracker 汇总各 worker 的进度，串行地回调并保存断点
+type uploadProgressTracker struct {
+	mu         sync.Mutex
+	progress   UploadProgress
+	checkpoint *UploadCheckpoint
+	opts       *uploadOptions
+}
+
+func newUploadProgressTracker(checkpoint *UploadCheckpoint, opts *uploadOptions) *uploadProgressTracker {
+	return &uploadProgressTracker{
+		progress: UploadProgress{
+			BytesDone:   checkpoint.CompletedBytes(),
+			TotalBytes:  int64(checkpoint.Size),
+			BlocksDone:  len(checkpoint.Checksums),
+			TotalBlocks: checkpoint.BlockNum,
+		},
+		checkpoint: checkpoint,
+		opts:       opts,
+	}
+}
+
+func (t *uploadProgressTracker) done(ctx context.Context, seq, bytes int, checksum string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.checkpoint.Checksums[seq] = checksum
+	if t.opts.checkpointStore != nil {
+		t.checkpoint.UpdatedAt = time.Now()
+		if err := t.opts.checkpointStore.Save(ctx, t.checkpoint); err != nil {
+			return err
+		}
+	}
+
+	t.progress.BytesDone += int64(bytes)
+	t.progress.BlocksDone++
+	for _, callback := range t.opts.progress {
+		callback(t.progress)
+	}
+
+	return nil
+}
+
+// byteLimiter 按字节数限制吞吐量
+type byteLimiter struct {
+	mu             sync.Mutex
+	bytesPerSecond int64
+	next           time.Time
+}
+
+func newByteLimiter(bytesPerSecond int64) *byteLimiter {
+	return &byteLimiter{bytesPerSecond: bytesPerSecond}
+}
+
+func (l *byteLimiter) waitN(ctx context.Context, n int) error {
+	if l.bytesPerSecond <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	slot := l.next
+	l.next = slot.Add(time.Duration(float64(n) / float64(l.bytesPerSecond) * float64(time.Second)))
+	l.mu.Unlock()
+
+	return sleepCtx(ctx, slot.Sub(now))
+}
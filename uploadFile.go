@@ -0,0 +1,122 @@
+package larki
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+)
+
+// 上传的目标类型
+const (
+	ParentTypeExplorer     = "explorer"
+	ParentTypeDocImage     = "doc_image"
+	ParentTypeDocFile      = "doc_file"
+	ParentTypeDocxImage    = "docx_image"
+	ParentTypeDocxFile     = "docx_file"
+	ParentTypeSheetImage   = "sheet_image"
+	ParentTypeSheetFile    = "sheet_file"
+	ParentTypeBitableImage = "bitable_image"
+	ParentTypeBitableFile  = "bitable_file"
+	ParentTypeCcmImport    = "ccm_import_open"
+)
+
+// UploadAllMaxSize 一次上传的文件大小上限，超过时使用分片上传
+const UploadAllMaxSize = 20 << 20
+
+// ErrSizeMismatch 声明的文件大小与实际读取的数据不一致
+var ErrSizeMismatch = errors.New("larki: declared size does not match reader")
+
+// WithUploadExtra 设置素材上传的 extra 参数，仅在上传素材时生效
+func WithUploadExtra(extra string) UploadOption {
+	return func(o *uploadOptions) {
+		o.extra = extra
+	}
+}
+
+// UploadFile 上传文件或素材，parentType 为 explorer 时上传到云空间文件夹，否则作为素材上传
+// 不超过 UploadAllMaxSize 时一次上传并携带 adler32 校验和，否则分片上传并逐片校验
+// reader 实现 io.ReaderAt 时分片可并发读取
+func (c *Client) UploadFile(ctx context.Context, name, parentType, parentNode string, size int, reader io.Reader, options ...UploadOption) (string, error) {
+	if size <= 0 {
+		return "", ErrSizeMismatch
+	}
+
+	if remaining, ok := remainingSize(reader); ok && remaining != int64(size) {
+		return "", ErrSizeMismatch
+	}
+
+	opts := newUploadOptions(options...)
+	if size <= UploadAllMaxSize {
+		data := make([]byte, size+1)
+		n, err := io.ReadFull(reader, data)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return "", err
+		}
+
+		if n != size {
+			return "", ErrSizeMismatch
+		}
+
+		data = data[:size]
+		checksum := blockChecksum(data)
+		if parentType == ParentTypeExplorer {
+			return c.uploadDocFileAll(ctx, name, parentType, parentNode, size, checksum, bytes.NewReader(data))
+		}
+
+		return c.uploadDocMediaAll(ctx, name, parentType, parentNode, opts.extra, size, checksum, bytes.NewReader(data))
+	}
+
+	var api multipartApi
+	if parentType == ParentTypeExplorer {
+		api = c.docFileMultipartApi(name, parentType, parentNode, size)
+	} else {
+		api = c.docMediaMultipartApi(name, parentType, parentNode, opts.extra, size)
+	}
+
+	// ReaderAt 以绝对偏移读取，需要从 reader 的当前位置开始截取
+	if readerAt, ok := reader.(io.ReaderAt); ok {
+		if seeker, ok := reader.(io.Seeker); ok {
+			if offset, err := seeker.Seek(0, io.SeekCurrent); err == nil {
+				return c.uploadMultiPartAt(ctx, api, io.NewSectionReader(readerAt, offset, int64(size)), options...)
+			}
+		}
+	}
+
+	return c.uploadMultiPart(ctx, api, reader, options...)
+}
+
+// UploadFile 上传文件或素材，按大小自动选择一次上传或分片上传
+func UploadFile(ctx context.Context, name, parentType, parentNode string, size int, reader io.Reader, options ...UploadOption) (string, error) {
+	return GlobalClient.UploadFile(ctx, name, parentType, parentNode, size, reader, options...)
+}
+
+// remainingSize 尽可能获取 reader 中剩余的字节数
+func remainingSize(reader io.Reader) (int64, bool) {
+	switch r := reader.(type) {
+	case interface{ Len() int }:
+		return int64(r.Len()), true
+	case *os.File:
+		info, err := r.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0, false
+		}
+
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+
+		return info.Size() - offset, true
+	case *io.SectionReader:
+		offset, err := r.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return 0, false
+		}
+
+		return r.Size() - offset, true
+	}
+
+	return 0, false
+}
@@ -3,7 +3,6 @@ package larki
 import (
 	"crypto/sha1"
 	"encoding/hex"
-	"fmt"
 	"strings"
 
 	"github.com/bytedance/sonic"
@@ -12,11 +11,6 @@ import (
 
 const larkErrFormat = "lark %s failed, code: %d, msg: %s"
 
-// newLarkError 构造 飞书服务端报错
-func newLarkError(code int, msg, field string) error {
-	return fmt.Errorf(larkErrFormat, field, code, msg)
-}
-
 // ParseTextContent 解析文本消息内容
 func ParseTextContent(text string) (string, bool) {
 	var content textContent
@@ -112,3 +106,17 @@ func CestSign(origin []byte) string {
 	encrypt.Write(origin)
 	return hex.EncodeToString(encrypt.Sum(nil))
 }
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
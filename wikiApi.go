@@ -0,0 +1,376 @@
+package larki
+
+import (
+	"context"
+
+	larkwiki "github.com/larksuite/oapi-sdk-go/v3/service/wiki/v2"
+)
+
+// 知识库节点类型
+const (
+	WikiNodeOrigin   = "origin"
+	WikiNodeShortcut = "shortcut"
+)
+
+// IterWikiSpaces 分页迭代应用可访问的知识空间
+func (c *Client) IterWikiSpaces(options ...PageOption) *Pager[*larkwiki.Space] {
+	return newPager(func(ctx context.Context, pageToken string, pageSize int) ([]*larkwiki.Space, string, bool, error) {
+		builder := larkwiki.NewListSpaceReqBuilder().PageToken(pageToken)
+		if pageSize > 0 {
+			builder.PageSize(pageSize)
+		}
+
+		var resp *larkwiki.ListSpaceResp
+		err := c.retry(ctx, retrySafe, func() (err error) {
+			resp, err = c.Wiki.Space.List(ctx, builder.Build())
+			if err != nil {
+				return err
+			}
+
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "ListWikiSpaces")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, "", false, err
+		}
+
+		return resp.Data.Items, derefString(resp.Data.PageToken), derefBool(resp.Data.HasMore), nil
+	}, 50, options...)
+}
+
+// IterWikiSpaces 分页迭代知识空间
+func IterWikiSpaces(options ...PageOption) *Pager[*larkwiki.Space] {
+	return GlobalClient.IterWikiSpaces(options...)
+}
+
+// ListWikiSpaces 获取应用可访问的全部知识空间
+func (c *Client) ListWikiSpaces(ctx context.Context) ([]*larkwiki.Space, error) {
+	return c.IterWikiSpaces().Collect(ctx)
+}
+
+// ListWikiSpaces 获取全部知识空间
+func ListWikiSpaces(ctx context.Context) ([]*larkwiki.Space, error) {
+	return GlobalClient.ListWikiSpaces(ctx)
+}
+
+// GetWikiSpace 获取知识空间信息
+func (c *Client) GetWikiSpace(ctx context.Context, spaceId string) (*larkwiki.Space, error) {
+	req := larkwiki.NewGetSpaceReqBuilder().SpaceId(spaceId).Build()
+
+	var resp *larkwiki.GetSpaceResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Wiki.Space.Get(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "GetWikiSpace")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.Space, nil
+}
+
+// GetWikiSpace 获取知识空间信息
+func GetWikiSpace(ctx context.Context, spaceId string) (*larkwiki.Space, error) {
+	return GlobalClient.GetWikiSpace(ctx, spaceId)
+}
+
+// IterWikiNodes 分页迭代 parentNodeToken 下的子节点，parentNodeToken 为空时迭代一级节点
+func (c *Client) IterWikiNodes(spaceId, parentNodeToken string, options ...PageOption) *Pager[*larkwiki.Node] {
+	return newPager(func(ctx context.Context, pageToken string, pageSize int) ([]*larkwiki.Node, string, bool, error) {
+		builder := larkwiki.NewListSpaceNodeReqBuilder().SpaceId(spaceId).PageToken(pageToken)
+		if parentNodeToken != "" {
+			builder.ParentNodeToken(parentNodeToken)
+		}
+
+		if pageSize > 0 {
+			builder.PageSize(pageSize)
+		}
+
+		var resp *larkwiki.ListSpaceNodeResp
+		err := c.retry(ctx, retrySafe, func() (err error) {
+			resp, err = c.Wiki.SpaceNode.List(ctx, builder.Build())
+			if err != nil {
+				return err
+			}
+
+			if !resp.Success() {
+				return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "ListWikiNodes")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, "", false, err
+		}
+
+		return resp.Data.Items, derefString(resp.Data.PageToken), derefBool(resp.Data.HasMore), nil
+	}, 50, options...)
+}
+
+// IterWikiNodes 分页迭代知识库子节点
+func IterWikiNodes(spaceId, parentNodeToken string, options ...PageOption) *Pager[*larkwiki.Node] {
+	return GlobalClient.IterWikiNodes(spaceId, parentNodeToken, options...)
+}
+
+// ListWikiNodes 获取 parentNodeToken 下的全部子节点
+func (c *Client) ListWikiNodes(ctx context.Context, spaceId, parentNodeToken string) ([]*larkwiki.Node, error) {
+	return c.IterWikiNodes(spaceId, parentNodeToken).Collect(ctx)
+}
+
+// ListWikiNodes 获取知识库子节点
+func ListWikiNodes(ctx context.Context, spaceId, parentNodeToken string) ([]*larkwiki.Node, error) {
+	return GlobalClient.ListWikiNodes(ctx, spaceId, parentNodeToken)
+}
+
+// GetWikiNode 由节点 token 获取知识库节点
+func (c *Client) GetWikiNode(ctx context.Context, nodeToken string) (*larkwiki.Node, error) {
+	return c.getWikiNode(ctx, nodeToken, "", "GetWikiNode")
+}
+
+// GetWikiNode 由节点 token 获取知识库节点
+func GetWikiNode(ctx context.Context, nodeToken string) (*larkwiki.Node, error) {
+	return GlobalClient.GetWikiNode(ctx, nodeToken)
+}
+
+// GetWikiNodeByObj 由云文档 token 和类型获取其所在的知识库节点
+func (c *Client) GetWikiNodeByObj(ctx context.Context, objToken, objType string) (*larkwiki.Node, error) {
+	return c.getWikiNode(ctx, objToken, objType, "GetWikiNodeByObj")
+}
+
+// GetWikiNodeByObj 由云文档获取知识库节点
+func GetWikiNodeByObj(ctx context.Context, objToken, objType string) (*larkwiki.Node, error) {
+	return GlobalClient.GetWikiNodeByObj(ctx, objToken, objType)
+}
+
+func (c *Client) getWikiNode(ctx context.Context, token, objType, op string) (*larkwiki.Node, error) {
+	builder := larkwiki.NewGetNodeSpaceReqBuilder().Token(token)
+	if objType != "" {
+		builder.ObjType(objType)
+	}
+
+	req := builder.Build()
+
+	var resp *larkwiki.GetNodeSpaceResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Wiki.Space.GetNode(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, op)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Data.Node == nil {
+		return nil, newNotFoundError("wiki node not found", op)
+	}
+
+	return resp.Data.Node, nil
+}
+
+// ResolveWikiNode 将知识库节点 token 解析为实际云文档的 token 和类型
+func (c *Client) ResolveWikiNode(ctx context.Context, nodeToken string) (string, string, error) {
+	node, err := c.GetWikiNode(ctx, nodeToken)
+	if err != nil {
+		return "", "", err
+	}
+
+	return derefString(node.ObjToken), derefString(node.ObjType), nil
+}
+
+// ResolveWikiNode 将知识库节点 token 解析为实际云文档
+func ResolveWikiNode(ctx context.Context, nodeToken string) (string, string, error) {
+	return GlobalClient.ResolveWikiNode(ctx, nodeToken)
+}
+
+// CreateWikiNode 在 parentNodeToken 下创建 objType 类型的文档节点，objType 为 docx、sheet、bitable 等
+// parentNodeToken 为空时创建一级节点
+func (c *Client) CreateWikiNode(ctx context.Context, spaceId, parentNodeToken, objType, title string) (*larkwiki.Node, error) {
+	node := larkwiki.NewNodeBuilder().
+		ObjType(objType).
+		NodeType(WikiNodeOrigin).
+		Title(title)
+	if parentNodeToken != "" {
+		node.ParentNodeToken(parentNodeToken)
+	}
+
+	return c.createWikiNode(ctx, spaceId, node.Build(), "CreateWikiNode")
+}
+
+// CreateWikiNode 在知识库中创建文档节点
+func CreateWikiNode(ctx context.Context, spaceId, parentNodeToken, objType, title string) (*larkwiki.Node, error) {
+	return GlobalClient.CreateWikiNode(ctx, spaceId, parentNodeToken, objType, title)
+}
+
+// CreateWikiShortcut 在 parentNodeToken 下创建指向 originNodeToken 的快捷方式
+func (c *Client) CreateWikiShortcut(ctx context.Context, spaceId, parentNodeToken, originNodeToken string) (*larkwiki.Node, error) {
+	node := larkwiki.NewNodeBuilder().
+		NodeType(WikiNodeShortcut).
+		OriginNodeToken(originNodeToken)
+	if parentNodeToken != "" {
+		node.ParentNodeToken(parentNodeToken)
+	}
+
+	return c.createWikiNode(ctx, spaceId, node.Build(), "CreateWikiShortcut")
+}
+
+// CreateWikiShortcut 在知识库中创建快捷方式
+func CreateWikiShortcut(ctx context.Context, spaceId, parentNodeToken, originNodeToken string) (*larkwiki.Node, error) {
+	return GlobalClient.CreateWikiShortcut(ctx, spaceId, parentNodeToken, originNodeToken)
+}
+
+func (c *Client) createWikiNode(ctx context.Context, spaceId string, node *larkwiki.Node, op string) (*larkwiki.Node, error) {
+	req := larkwiki.NewCreateSpaceNodeReqBuilder().SpaceId(spaceId).Node(node).Build()
+
+	var resp *larkwiki.CreateSpaceNodeResp
+	err := c.retry(ctx, retryRateLimited, func() (err error) {
+		resp, err = c.Wiki.SpaceNode.Create(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, op)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.Node, nil
+}
+
+// MoveWikiNode 移动节点及其子节点到 targetSpaceId 的 targetParentToken 下
+// targetSpaceId 为空时在原空间内移动，targetParentToken 为空时移动为一级节点
+func (c *Client) MoveWikiNode(ctx context.Context, spaceId, nodeToken, targetSpaceId, targetParentToken string) (*larkwiki.Node, error) {
+	body := larkwiki.NewMoveSpaceNodeReqBodyBuilder()
+	if targetSpaceId != "" {
+		body.TargetSpaceId(targetSpaceId)
+	}
+
+	if targetParentToken != "" {
+		body.TargetParentToken(targetParentToken)
+	}
+
+	req := larkwiki.NewMoveSpaceNodeReqBuilder().
+		SpaceId(spaceId).
+		NodeToken(nodeToken).
+		Body(body.Build()).
+		Build()
+
+	var resp *larkwiki.MoveSpaceNodeResp
+	err := c.retry(ctx, retrySafe, func() (err error) {
+		resp, err = c.Wiki.SpaceNode.Move(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "MoveWikiNode")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.Node, nil
+}
+
+// MoveWikiNode 移动知识库节点
+func MoveWikiNode(ctx context.Context, spaceId, nodeToken, targetSpaceId, targetParentToken string) (*larkwiki.Node, error) {
+	return GlobalClient.MoveWikiNode(ctx, spaceId, nodeToken, targetSpaceId, targetParentToken)
+}
+
+// CopyWikiNode 复制节点到 targetSpaceId 的 targetParentToken 下，title 为空时沿用原标题
+func (c *Client) CopyWikiNode(ctx context.Context, spaceId, nodeToken, targetSpaceId, targetParentToken, title string) (*larkwiki.Node, error) {
+	body := larkwiki.NewCopySpaceNodeReqBodyBuilder()
+	if targetSpaceId != "" {
+		body.TargetSpaceId(targetSpaceId)
+	}
+
+	if targetParentToken != "" {
+		body.TargetParentToken(targetParentToken)
+	}
+
+	if title != "" {
+		body.Title(title)
+	}
+
+	req := larkwiki.NewCopySpaceNodeReqBuilder().
+		SpaceId(spaceId).
+		NodeToken(nodeToken).
+		Body(body.Build()).
+		Build()
+
+	var resp *larkwiki.CopySpaceNodeResp
+	err := c.retry(ctx, retryRateLimited, func() (err error) {
+		resp, err = c.Wiki.SpaceNode.Copy(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "CopyWikiNode")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Data.Node, nil
+}
+
+// CopyWikiNode 复制知识库节点
+func CopyWikiNode(ctx context.Context, spaceId, nodeToken, targetSpaceId, targetParentToken, title string) (*larkwiki.Node, error) {
+	return GlobalClient.CopyWikiNode(ctx, spaceId, nodeToken, targetSpaceId, targetParentToken, title)
+}
+
+// UpdateWikiNodeTitle 更新节点标题，目前仅支持文档类型的节点
+func (c *Client) UpdateWikiNodeTitle(ctx context.Context, spaceId, nodeToken, title string) error {
+	req := larkwiki.NewUpdateTitleSpaceNodeReqBuilder().
+		SpaceId(spaceId).
+		NodeToken(nodeToken).
+		Body(larkwiki.NewUpdateTitleSpaceNodeReqBodyBuilder().Title(title).Build()).
+		Build()
+
+	return c.retry(ctx, retrySafe, func() error {
+		resp, err := c.Wiki.SpaceNode.UpdateTitle(ctx, req)
+		if err != nil {
+			return err
+		}
+
+		if !resp.Success() {
+			return newLarkRespError(resp.ApiResp, resp.Code, resp.Msg, "UpdateWikiNodeTitle")
+		}
+
+		return nil
+	})
+}
+
+// UpdateWikiNodeTitle 更新知识库节点标题
+func UpdateWikiNodeTitle(ctx context.Context, spaceId, nodeToken, title string) error {
+	return GlobalClient.UpdateWikiNodeTitle(ctx, spaceId, nodeToken, title)
+}
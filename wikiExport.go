@@ -0,0 +1,430 @@
+package larki
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bytedance/sonic"
+)
+
+const (
+	// WikiManifestName 导出目录中的清单文件名
+	WikiManifestName = "manifest.json"
+	// wikiAssetDirName 导出目录中存放图片的子目录
+	wikiAssetDirName = "assets"
+)
+
+// WikiManifest 知识库导出清单，记录每个节点上次导出时的状态，用于增量导出
+type WikiManifest struct {
+	SpaceId string `json:"space_id"`
+	// RootNode 导出的根节点 token，导出整个知识空间时为空
+	RootNode string `json:"root_node,omitempty"`
+	// Nodes 以节点 token 为键
+	Nodes map[string]*WikiManifestNode `json:"nodes"`
+}
+
+// WikiManifestNode 清单中的一个节点
+type WikiManifestNode struct {
+	NodeToken   string `json:"node_token"`
+	ParentToken string `json:"parent_token,omitempty"`
+	ObjToken    string `json:"obj_token"`
+	ObjType     string `json:"obj_type"`
+	Title       string `json:"title"`
+	// Path 导出的 Markdown 文件相对导出目录的路径，以 / 分隔，未导出的节点为空
+	Path     string `json:"path,omitempty"`
+	EditTime string `json:"edit_time,omitempty"`
+	Revision int    `json:"revision,omitempty"`
+}
+
+// WikiExportResult 导出结果，路径相对导出目录
+type WikiExportResult struct {
+	Exported []string
+	// Unchanged 自上次导出后没有修改的文档数
+	Unchanged int
+	// Skipped 无法导出为 Markdown 的节点 token，例如表格、多维表格和快捷方式
+	Skipped []string
+	Removed []string
+}
+
+// WikiExportOption 知识库导出配置
+type WikiExportOption func(*wikiExportOptions)
+
+type wikiExportOptions struct {
+	rootNode    string
+	concurrency int
+	prune       bool
+	force       bool
+	markdown    []MarkdownOption
+}
+
+// WithWikiExportRoot 只导出 nodeToken 的子节点，不同的根节点应导出到不同的目录
+func WithWikiExportRoot(nodeToken string) WikiExportOption {
+	return func(o *wikiExportOptions) {
+		o.rootNode = nodeToken
+	}
+}
+
+// WithWikiExportConcurrency 同时导出的文档数量，默认为 4
+func WithWikiExportConcurrency(concurrency int) WikiExportOption {
+	return func(o *wikiExportOptions) {
+		if concurrency > 0 {
+			o.concurrency = concurrency
+		}
+	}
+}
+
+// WithWikiExportPrune 删除知识库中已不存在的节点对应的文件
+func WithWikiExportPrune() WikiExportOption {
+	return func(o *wikiExportOptions) {
+		o.prune = true
+	}
+}
+
+// WithWikiExportForce 忽略清单，重新导出所有文档
+func WithWikiExportForce() WikiExportOption {
+	return func(o *wikiExportOptions) {
+		o.force = true
+	}
+}
+
+// WithWikiExportMarkdown 设置转换 Markdown 时的配置，图片路径前缀由导出器决定
+func WithWikiExportMarkdown(options ...MarkdownOption) WikiExportOption {
+	return func(o *wikiExportOptions) {
+		o.markdown = append(o.markdown, options...)
+	}
+}
+
+// wikiExportJob 一个需要重新导出的文档
+type wikiExportJob struct {
+	entry *WikiManifestNode
+	// oldPath 上次导出的路径，节点改名或移动时与 entry.Path 不同
+	oldPath string
+}
+
+type wikiExporter struct {
+	client *Client
+	outDir string
+	opts   *wikiExportOptions
+
+	old *WikiManifest
+	// paths 本次导出的所有文件路径，删除旧文件时跳过
+	paths map[string]bool
+
+	mu     sync.Mutex
+	nodes  map[string]*WikiManifestNode
+	result *WikiExportResult
+}
+
+// ExportWikiMarkdown 将知识空间导出为 Markdown 目录，节点层级对应目录层级，图片保存在 outDir/assets 下
+// 有子节点的文档导出为 标题.md 与同名目录；清单记录每个节点的版本，再次导出时只重新获取修改过的文档
+// 出错时已导出的文档仍会写入清单；outDir 中已有其他知识空间或其他根节点的导出清单时返回错误
+func (c *Client) ExportWikiMarkdown(ctx context.Context, spaceId, outDir string, options ...WikiExportOption) (*WikiExportResult, error) {
+	opts := &wikiExportOptions{concurrency: 4}
+	for _, option := range options {
+		option(opts)
+	}
+
+	old, err := LoadWikiManifest(filepath.Join(outDir, WikiManifestName))
+	if err != nil {
+		return nil, err
+	}
+
+	// 清单与本次导出范围不一致时，prune 会删除范围外的全部文件，增量记录也会混在一起
+	if len(old.Nodes) > 0 && (old.SpaceId != spaceId || old.RootNode != opts.rootNode) {
+		return nil, fmt.Errorf("larki: %s in %s belongs to space %s root %q, not space %s root %q",
+			WikiManifestName, outDir, old.SpaceId, old.RootNode, spaceId, opts.rootNode)
+	}
+
+	e := &wikiExporter{
+		client: c,
+		outDir: outDir,
+		opts:   opts,
+		old:    old,
+		paths:  make(map[string]bool),
+		nodes:  make(map[string]*WikiManifestNode, len(old.Nodes)),
+		result: &WikiExportResult{},
+	}
+
+	// 未开启 prune 时保留已删除节点的记录，以便之后清理
+	if !opts.prune {
+		for token, node := range old.Nodes {
+			e.nodes[token] = node
+		}
+	}
+
+	seen := make(map[string]bool)
+	var jobs []*wikiExportJob
+	err = e.walk(ctx, spaceId, opts.rootNode, "", map[string]bool{wikiAssetDirName: true, WikiManifestName: true}, seen, &jobs)
+	if err == nil {
+		err = e.export(ctx, jobs)
+	}
+
+	if err == nil && opts.prune {
+		err = e.prune(seen)
+	}
+
+	sort.Strings(e.result.Exported)
+	sort.Strings(e.result.Removed)
+
+	manifest := &WikiManifest{SpaceId: spaceId, RootNode: opts.rootNode, Nodes: e.nodes}
+	if saveErr := saveWikiManifest(filepath.Join(outDir, WikiManifestName), manifest); err == nil {
+		err = saveErr
+	}
+
+	return e.result, err
+}
+
+// ExportWikiMarkdown 将知识空间导出为 Markdown 目录
+func ExportWikiMarkdown(ctx context.Context, spaceId, outDir string, options ...WikiExportOption) (*WikiExportResult, error) {
+	return GlobalClient.ExportWikiMarkdown(ctx, spaceId, outDir, options...)
+}
+
+// LoadWikiManifest 读取导出清单，文件不存在时返回空清单
+func LoadWikiManifest(name string) (*WikiManifest, error) {
+	manifest := &WikiManifest{Nodes: make(map[string]*WikiManifestNode)}
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return manifest, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = sonic.Unmarshal(data, manifest); err != nil {
+		return nil, err
+	}
+
+	if manifest.Nodes == nil {
+		manifest.Nodes = make(map[string]*WikiManifestNode)
+	}
+
+	return manifest, nil
+}
+
+// saveWikiManifest 按键排序写入清单，便于纳入版本管理
+func saveWikiManifest(name string, manifest *WikiManifest) error {
+	data, err := sonic.ConfigStd.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(name, bytes.NewReader(data))
+}
+
+// walk 列出 parentToken 的子节点并计算导出路径，used 为目录中已占用的名称
+func (e *wikiExporter) walk(ctx context.Context, spaceId, parentToken, dir string, used, seen map[string]bool, jobs *[]*wikiExportJob) error {
+	nodes, err := e.client.ListWikiNodes(ctx, spaceId, parentToken)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		token := derefString(node.NodeToken)
+		seen[token] = true
+
+		entry := &WikiManifestNode{
+			NodeToken:   token,
+			ParentToken: derefString(node.ParentNodeToken),
+			ObjToken:    derefString(node.ObjToken),
+			ObjType:     derefString(node.ObjType),
+			Title:       derefString(node.Title),
+			EditTime:    derefString(node.ObjEditTime),
+		}
+
+		name := wikiFileName(entry.Title, token, used)
+		exportable := entry.ObjType == "docx" && derefString(node.NodeType) != WikiNodeShortcut
+		if exportable {
+			entry.Path = path.Join(dir, name+".md")
+			e.paths[entry.Path] = true
+			e.plan(entry, jobs)
+		} else {
+			e.result.Skipped = append(e.result.Skipped, token)
+			e.nodes[token] = entry
+		}
+
+		// 快捷方式的子节点属于原节点，不重复导出
+		if derefBool(node.HasChild) && derefString(node.NodeType) != WikiNodeShortcut {
+			if err = e.walk(ctx, spaceId, token, path.Join(dir, name), make(map[string]bool), seen, jobs); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// plan 比较清单判断文档是否需要重新导出
+func (e *wikiExporter) plan(entry *WikiManifestNode, jobs *[]*wikiExportJob) {
+	old := e.old.Nodes[entry.NodeToken]
+	if old != nil && !e.opts.force && old.EditTime == entry.EditTime && old.Path == entry.Path {
+		if _, err := os.Stat(filepath.Join(e.outDir, filepath.FromSlash(entry.Path))); err == nil {
+			entry.Revision = old.Revision
+			e.nodes[entry.NodeToken] = entry
+			e.result.Unchanged++
+			return
+		}
+	}
+
+	job := &wikiExportJob{entry: entry}
+	if old != nil {
+		job.oldPath = old.Path
+	}
+
+	*jobs = append(*jobs, job)
+}
+
+// export 并发导出文档，遇到第一个错误时停止
+func (e *wikiExporter) export(ctx context.Context, jobs []*wikiExportJob) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	queue := make(chan *wikiExportJob)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i := 0; i < e.opts.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				if ctx.Err() != nil {
+					continue
+				}
+
+				if err := e.exportDoc(ctx, job); err != nil {
+					once.Do(func() {
+						firstErr = err
+						cancel()
+					})
+				}
+			}
+		}()
+	}
+
+feed:
+	for _, job := range jobs {
+		select {
+		case queue <- job:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+
+	close(queue)
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+
+	return firstErr
+}
+
+func (e *wikiExporter) exportDoc(ctx context.Context, job *wikiExportJob) error {
+	entry := job.entry
+	doc, err := e.client.GetDocx(ctx, entry.ObjToken)
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(e.outDir, filepath.FromSlash(entry.Path))
+	assetDir := filepath.Join(e.outDir, wikiAssetDirName)
+	prefix, err := filepath.Rel(filepath.Dir(file), assetDir)
+	if err != nil {
+		return err
+	}
+
+	options := append(append([]MarkdownOption(nil), e.opts.markdown...), WithMarkdownAssetPrefix(filepath.ToSlash(prefix)))
+	markdown, err := e.client.ExportDocxMarkdown(ctx, entry.ObjToken, assetDir, options...)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+
+	if err = writeFileAtomic(file, strings.NewReader(markdown)); err != nil {
+		return err
+	}
+
+	entry.Revision = derefInt(doc.RevisionId)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.nodes[entry.NodeToken] = entry
+	e.result.Exported = append(e.result.Exported, entry.Path)
+	if job.oldPath != "" && job.oldPath != entry.Path {
+		e.removeFile(job.oldPath)
+	}
+
+	return nil
+}
+
+// prune 删除本次遍历中不存在的节点的文件
+func (e *wikiExporter) prune(seen map[string]bool) error {
+	for token, old := range e.old.Nodes {
+		if seen[token] || old.Path == "" {
+			continue
+		}
+
+		e.removeFile(old.Path)
+	}
+
+	return nil
+}
+
+// removeFile 删除导出的文件及因此变空的目录
+func (e *wikiExporter) removeFile(rel string) {
+	if e.paths[rel] {
+		return
+	}
+
+	file := filepath.Join(e.outDir, filepath.FromSlash(rel))
+	if err := os.Remove(file); err != nil {
+		return
+	}
+
+	e.result.Removed = append(e.result.Removed, rel)
+	for dir := filepath.Dir(file); dir != filepath.Clean(e.outDir); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+}
+
+// wikiFileName 由标题生成文件名，去除路径中不允许的字符，同名时追加节点 token 后缀
+func wikiFileName(title, token string, used map[string]bool) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < ' ' {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(title))
+	name = strings.Trim(name, ".")
+	if name == "" {
+		name = token
+	}
+
+	if used[strings.ToLower(name)] {
+		name += "-" + token[len(token)-minInt(6, len(token)):]
+	}
+
+	used[strings.ToLower(name)] = true
+	return name
+}
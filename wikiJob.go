@@ -0,0 +1,244 @@
+package larki
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	larkwiki "github.com/larksuite/oapi-sdk-go/v3/service/wiki/v2"
+)
+
+// WikiUploadStage 上传到知识库的阶段
+type WikiUploadStage int
+
+const (
+	WikiUploadUploading WikiUploadStage = iota
+	WikiUploadImporting
+	WikiUploadMoving
+	WikiUploadDone
+	WikiUploadFailed
+	WikiUploadCanceled
+)
+
+func (s WikiUploadStage) String() string {
+	switch s {
+	case WikiUploadUploading:
+		return "uploading"
+	case WikiUploadImporting:
+		return "importing"
+	case WikiUploadMoving:
+		return "moving"
+	case WikiUploadDone:
+		return "done"
+	case WikiUploadFailed:
+		return "failed"
+	case WikiUploadCanceled:
+		return "canceled"
+	}
+
+	return fmt.Sprintf("WikiUploadStage(%d)", int(s))
+}
+
+// WikiUploadJob 后台执行的 上传 -> 导入 -> 移动到知识库 流程
+type WikiUploadJob struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu           sync.Mutex
+	stage        WikiUploadStage
+	fileToken    string
+	importTicket string
+	docToken     string
+	nodeToken    string
+	results      []*larkwiki.MoveResult
+	err          error
+}
+
+// Stage 当前阶段
+func (j *WikiUploadJob) Stage() WikiUploadStage {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.stage
+}
+
+// FileToken 上传后的素材 token，上传完成前为空
+func (j *WikiUploadJob) FileToken() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.fileToken
+}
+
+// ImportTicket 导入任务的 ticket，创建导入任务前为空
+func (j *WikiUploadJob) ImportTicket() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.importTicket
+}
+
+// DocToken 导入生成的云文档 token，导入完成前为空
+func (j *WikiUploadJob) DocToken() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.docToken
+}
+
+// NodeToken 知识库节点 token，移动完成前为空
+func (j *WikiUploadJob) NodeToken() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.nodeToken
+}
+
+// Err 失败或取消的原因，未结束时为 nil
+func (j *WikiUploadJob) Err() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.err
+}
+
+// Done 流程结束时关闭
+func (j *WikiUploadJob) Done() <-chan struct{} {
+	return j.done
+}
+
+// Wait 等待流程结束，ctx 只控制等待本身，不会取消流程
+func (j *WikiUploadJob) Wait(ctx context.Context) ([]*larkwiki.MoveResult, error) {
+	select {
+	case <-j.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	return j.result()
+}
+
+// result 流程结束后的结果
+func (j *WikiUploadJob) result() ([]*larkwiki.MoveResult, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.results, j.err
+}
+
+// Cancel 取消流程，已经完成的阶段不会回滚
+func (j *WikiUploadJob) Cancel() {
+	j.cancel()
+}
+
+func (j *WikiUploadJob) update(fn func(j *WikiUploadJob)) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	fn(j)
+}
+
+// UploadToWikiAsync 在后台上传文件到知识库并立即返回，流程在 ctx 取消或调用 Cancel 时停止
+// 需要在请求返回后继续执行时应传入不随请求结束的 ctx；流程结束前不要再使用 reader
+func (c *Client) UploadToWikiAsync(ctx context.Context,
+	name, ext, docType, spaceId, parentNode string,
+	size int, reader io.Reader, options ...PollOption,
+) *WikiUploadJob {
+	ctx, cancel := context.WithCancel(ctx)
+	job := &WikiUploadJob{
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	go func() {
+		defer close(job.done)
+		defer cancel()
+
+		err := c.runWikiUpload(ctx, job, name, ext, docType, spaceId, parentNode, size, reader, options...)
+		job.update(func(j *WikiUploadJob) {
+			switch {
+			case err == nil:
+				j.stage = WikiUploadDone
+			case ctx.Err() != nil:
+				j.stage = WikiUploadCanceled
+				j.err = err
+			default:
+				j.stage = WikiUploadFailed
+				j.err = err
+			}
+		})
+	}()
+
+	return job
+}
+
+// UploadToWikiAsync 在后台上传文件到知识库并立即返回
+func UploadToWikiAsync(ctx context.Context,
+	name, ext, docType, spaceId, parentNode string,
+	size int, reader io.Reader, options ...PollOption,
+) *WikiUploadJob {
+	return GlobalClient.UploadToWikiAsync(ctx, name, ext, docType, spaceId, parentNode, size, reader, options...)
+}
+
+func (c *Client) runWikiUpload(ctx context.Context, job *WikiUploadJob,
+	name, ext, docType, spaceId, parentNode string,
+	size int, reader io.Reader, options ...PollOption,
+) error {
+	extras := fmt.Sprintf(`{"file_extension":"%s", "obj_type": "%s"}`, ext, docType)
+	fileToken, err := c.UploadDocMedia(ctx,
+		fmt.Sprintf("%s-%d.%s", name, time.Now().UnixMilli(), ext),
+		ParentTypeCcmImport,
+		"", extras, size, reader)
+	if err != nil {
+		return err
+	}
+
+	job.update(func(j *WikiUploadJob) {
+		j.fileToken = fileToken
+		j.stage = WikiUploadImporting
+	})
+
+	ticket, err := c.ImportDoc(ctx, ext, fileToken, docType, name, 1, "")
+	if err != nil {
+		return err
+	}
+
+	job.update(func(j *WikiUploadJob) {
+		j.importTicket = ticket
+	})
+
+	status, err := c.WaitImportDoc(ctx, ticket, options...)
+	if err != nil {
+		return err
+	}
+
+	docToken := derefString(status.Token)
+	if docToken == "" {
+		return fmt.Errorf("larki: import task %s finished without doc token", ticket)
+	}
+
+	job.update(func(j *WikiUploadJob) {
+		j.docToken = docToken
+		j.stage = WikiUploadMoving
+	})
+
+	resp, err := c.MoveDocToWiki(ctx, spaceId, docType, docToken, parentNode)
+	if err != nil {
+		return err
+	}
+
+	if resp.TaskId == nil {
+		job.update(func(j *WikiUploadJob) {
+			j.nodeToken = derefString(resp.WikiToken)
+		})
+		return nil
+	}
+
+	results, err := c.WaitMoveDocToWiki(ctx, *resp.TaskId, options...)
+	if err != nil {
+		return err
+	}
+
+	job.update(func(j *WikiUploadJob) {
+		j.results = results
+		if len(results) > 0 && results[0].Node != nil {
+			j.nodeToken = derefString(results[0].Node.NodeToken)
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,525 @@
+package larki
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bytedance/sonic"
+)
+
+// WikiMappingName 本地目录中默认的路径与知识库节点对应关系文件名
+const WikiMappingName = ".larki-wiki.json"
+
+// WikiMapping 本地路径与知识库节点的对应关系
+type WikiMapping struct {
+	SpaceId    string `json:"space_id"`
+	ParentNode string `json:"parent_node,omitempty"`
+	// Pages 以 Markdown 文件相对路径为键
+	Pages map[string]*WikiMappingEntry `json:"pages"`
+	// Dirs 没有同名 Markdown 文件的目录，以目录相对路径为键
+	Dirs map[string]*WikiMappingEntry `json:"dirs"`
+}
+
+// WikiMappingEntry 一个本地路径对应的节点
+type WikiMappingEntry struct {
+	NodeToken string `json:"node_token"`
+	ObjToken  string `json:"obj_token"`
+	// Hash 上次发布时文件内容的 sha256，目录为空
+	Hash string `json:"hash,omitempty"`
+}
+
+// WikiPublishResult 发布结果，路径相对本地目录
+type WikiPublishResult struct {
+	Created  []string
+	Updated  []string
+	Archived []string
+	// Unchanged 内容未变化而跳过的页面数
+	Unchanged int
+}
+
+// WikiPublishOption 知识库发布配置
+type WikiPublishOption func(*wikiPublishOptions)
+
+type wikiPublishOptions struct {
+	mappingPath string
+	archiveNode string
+	importDoc   bool
+	poll        []PollOption
+	excludes    []string
+	write       []DocxWriteOption
+}
+
+// WithPublishMapping 对应关系文件的路径，默认为本地目录下的 WikiMappingName
+func WithPublishMapping(path string) WikiPublishOption {
+	return func(o *wikiPublishOptions) {
+		o.mappingPath = path
+	}
+}
+
+// WithPublishArchive 将本地已删除的页面移动到 nodeToken 下，不设置时保留这些节点
+func WithPublishArchive(nodeToken string) WikiPublishOption {
+	return func(o *wikiPublishOptions) {
+		o.archiveNode = nodeToken
+	}
+}
+
+// WithPublishImport 新页面通过上传并导入 Markdown 文件创建，图片等相对路径资源不会被导入
+// 默认直接创建文档并写入 Block
+func WithPublishImport(options ...PollOption) WikiPublishOption {
+	return func(o *wikiPublishOptions) {
+		o.importDoc = true
+		o.poll = append(o.poll, options...)
+	}
+}
+
+// WithPublishExclude 排除匹配的路径，规则同 path.Match，同时匹配相对路径和文件名
+func WithPublishExclude(patterns ...string) WikiPublishOption {
+	return func(o *wikiPublishOptions) {
+		o.excludes = append(o.excludes, patterns...)
+	}
+}
+
+// WithPublishRemoteImages 允许下载页面中 http(s) 地址的图片，client 为 nil 时使用 http.DefaultClient
+// 默认只读取本地目录下的图片
+func WithPublishRemoteImages(client *http.Client) WikiPublishOption {
+	return func(o *wikiPublishOptions) {
+		o.write = append(o.write, WithRemoteImages(client))
+	}
+}
+
+type wikiPublisher struct {
+	client     *Client
+	localDir   string
+	spaceId    string
+	parentNode string
+	opts       *wikiPublishOptions
+
+	mapping *WikiMapping
+	// pages 本地存在的 Markdown 文件
+	pages map[string]bool
+	// nodes 本次发布中路径（不含 .md）对应的节点 token
+	nodes  map[string]string
+	result *WikiPublishResult
+}
+
+// PublishWikiMarkdown 将本地目录中的 Markdown 文件发布到知识空间 parentNode 下，目录层级对应节点层级
+// a.md 与目录 a 同时存在时 a 下的页面作为 a.md 的子节点，否则为目录创建同名空文档作为父节点
+// 已发布的页面在内容变化时增量更新，标题取自开头的一级标题或文件名；以 . 开头的文件和目录会被忽略
+// 出错时已完成的发布仍会写入对应关系文件
+func (c *Client) PublishWikiMarkdown(ctx context.Context, localDir, spaceId, parentNode string, options ...WikiPublishOption) (*WikiPublishResult, error) {
+	opts := &wikiPublishOptions{mappingPath: filepath.Join(localDir, WikiMappingName)}
+	for _, option := range options {
+		option(opts)
+	}
+
+	// 页面可以引用本地目录下任意位置的图片
+	opts.write = append(opts.write, WithImageRoot(localDir))
+
+	mapping, err := LoadWikiMapping(opts.mappingPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if mapping.SpaceId != "" && (mapping.SpaceId != spaceId || mapping.ParentNode != parentNode) {
+		return nil, errors.New("larki: wiki mapping was created for another space or parent node")
+	}
+
+	mapping.SpaceId, mapping.ParentNode = spaceId, parentNode
+
+	p := &wikiPublisher{
+		client:     c,
+		localDir:   localDir,
+		spaceId:    spaceId,
+		parentNode: parentNode,
+		opts:       opts,
+		mapping:    mapping,
+		pages:      make(map[string]bool),
+		nodes:      make(map[string]string),
+		result:     &WikiPublishResult{},
+	}
+
+	err = p.scan()
+	if err == nil {
+		err = p.publish(ctx)
+	}
+
+	if err == nil && opts.archiveNode != "" {
+		err = p.archive(ctx)
+	}
+
+	if saveErr := saveWikiMapping(opts.mappingPath, mapping); err == nil {
+		err = saveErr
+	}
+
+	return p.result, err
+}
+
+// PublishWikiMarkdown 将本地 Markdown 目录发布到知识库
+func PublishWikiMarkdown(ctx context.Context, localDir, spaceId, parentNode string, options ...WikiPublishOption) (*WikiPublishResult, error) {
+	return GlobalClient.PublishWikiMarkdown(ctx, localDir, spaceId, parentNode, options...)
+}
+
+// LoadWikiMapping 读取对应关系文件，文件不存在时返回空的对应关系
+func LoadWikiMapping(name string) (*WikiMapping, error) {
+	mapping := &WikiMapping{}
+	data, err := os.ReadFile(name)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	if err == nil {
+		if err = sonic.Unmarshal(data, mapping); err != nil {
+			return nil, err
+		}
+	}
+
+	if mapping.Pages == nil {
+		mapping.Pages = make(map[string]*WikiMappingEntry)
+	}
+
+	if mapping.Dirs == nil {
+		mapping.Dirs = make(map[string]*WikiMappingEntry)
+	}
+
+	return mapping, nil
+}
+
+func saveWikiMapping(name string, mapping *WikiMapping) error {
+	data, err := sonic.ConfigStd.MarshalIndent(mapping, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(name, bytes.NewReader(data))
+}
+
+// scan 列出本地目录中的 Markdown 文件
+func (p *wikiPublisher) scan() error {
+	return filepath.WalkDir(p.localDir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(p.localDir, name)
+		if err != nil {
+			return err
+		}
+
+		if rel == "." {
+			return nil
+		}
+
+		rel = filepath.ToSlash(rel)
+		if strings.HasPrefix(d.Name(), ".") || matchExclude(p.opts.excludes, rel) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.IsDir() && strings.EqualFold(path.Ext(rel), ".md") {
+			p.pages[rel] = true
+		}
+
+		return nil
+	})
+}
+
+func (p *wikiPublisher) publish(ctx context.Context) error {
+	pages := make([]string, 0, len(p.pages))
+	for rel := range p.pages {
+		pages = append(pages, rel)
+	}
+
+	sort.Strings(pages)
+	for _, rel := range pages {
+		// 未变化的页面也要解析父节点，使 archive 知道哪些目录节点仍在使用
+		if _, err := p.dir(ctx, path.Dir(rel)); err != nil {
+			return err
+		}
+
+		if _, err := p.page(ctx, rel); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// page 创建或更新页面并返回其节点 token
+func (p *wikiPublisher) page(ctx context.Context, rel string) (string, error) {
+	key := strings.TrimSuffix(rel, path.Ext(rel))
+	if token, ok := p.nodes[key]; ok {
+		return token, nil
+	}
+
+	file := filepath.Join(p.localDir, filepath.FromSlash(rel))
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	hash, err := fileHash(bytes.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+
+	entry := p.mapping.Pages[rel]
+	if entry != nil && entry.NodeToken == "" {
+		if err = p.adopt(ctx, rel, entry); err != nil {
+			return "", err
+		}
+	}
+
+	// 之前作为目录创建的节点，新增同名页面后改为页面的节点，目录下已有的页面保持不动
+	if dirEntry := p.mapping.Dirs[key]; entry == nil && dirEntry != nil {
+		entry = dirEntry
+		p.mapping.Pages[rel] = entry
+		delete(p.mapping.Dirs, key)
+	}
+
+	switch {
+	case entry != nil && entry.Hash == hash:
+		p.result.Unchanged++
+	case entry != nil:
+		if _, err = p.client.UpdateDocxMarkdown(ctx, entry.ObjToken, string(content), filepath.Dir(file), p.opts.write...); err != nil {
+			return "", err
+		}
+
+		entry.Hash = hash
+		p.result.Updated = append(p.result.Updated, rel)
+	default:
+		parent, err := p.dir(ctx, path.Dir(rel))
+		if err != nil {
+			return "", err
+		}
+
+		entry, err = p.create(ctx, rel, parent, content)
+		if err != nil {
+			return "", err
+		}
+
+		entry.Hash = hash
+		p.mapping.Pages[rel] = entry
+		p.result.Created = append(p.result.Created, rel)
+	}
+
+	p.nodes[key] = entry.NodeToken
+	return entry.NodeToken, nil
+}
+
+// create 在 parent 下创建页面
+func (p *wikiPublisher) create(ctx context.Context, rel, parent string, content []byte) (*WikiMappingEntry, error) {
+	dir := filepath.Dir(filepath.Join(p.localDir, filepath.FromSlash(rel)))
+	title := strings.TrimSuffix(path.Base(rel), path.Ext(rel))
+
+	if p.opts.importDoc {
+		job := p.client.UploadToWikiAsync(ctx, title, "md", "docx", p.spaceId, parent, len(content), bytes.NewReader(content), p.opts.poll...)
+		<-job.Done()
+
+		entry := &WikiMappingEntry{NodeToken: job.NodeToken(), ObjToken: job.DocToken()}
+		if _, err := job.result(); err != nil {
+			// 文档已导入，记录下来以便下次找回节点而不是重复创建
+			if entry.ObjToken != "" {
+				p.mapping.Pages[rel] = entry
+			}
+			return nil, err
+		}
+
+		return entry, nil
+	}
+
+	node, err := p.client.CreateWikiNode(ctx, p.spaceId, parent, "docx", title)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &WikiMappingEntry{NodeToken: derefString(node.NodeToken), ObjToken: derefString(node.ObjToken)}
+	if _, err = p.client.UpdateDocxMarkdown(ctx, entry.ObjToken, string(content), dir, p.opts.write...); err != nil {
+		// 节点已创建，记录下来以便下次更新而不是重复创建
+		p.mapping.Pages[rel] = entry
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// adopt 找回上次中断的导入所生成文档的节点，文档尚未移入知识库时重新移动
+func (p *wikiPublisher) adopt(ctx context.Context, rel string, entry *WikiMappingEntry) error {
+	node, err := p.client.GetWikiNodeByObj(ctx, entry.ObjToken, "docx")
+	if err == nil {
+		entry.NodeToken = derefString(node.NodeToken)
+		return nil
+	}
+
+	if !IsNotFound(err) {
+		return err
+	}
+
+	parent, err := p.dir(ctx, path.Dir(rel))
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.MoveDocToWiki(ctx, p.spaceId, "docx", entry.ObjToken, parent)
+	if err != nil {
+		return err
+	}
+
+	token := derefString(resp.WikiToken)
+	if resp.TaskId != nil {
+		results, err := p.client.WaitMoveDocToWiki(ctx, *resp.TaskId, p.opts.poll...)
+		if err != nil {
+			return err
+		}
+
+		if len(results) > 0 && results[0].Node != nil {
+			token = derefString(results[0].Node.NodeToken)
+		}
+	}
+
+	if token == "" {
+		return fmt.Errorf("larki: move %s to wiki returned no node", rel)
+	}
+
+	entry.NodeToken = token
+	return nil
+}
+
+// dir 返回目录对应的节点 token，必要时创建
+func (p *wikiPublisher) dir(ctx context.Context, rel string) (string, error) {
+	if rel == "." {
+		return p.parentNode, nil
+	}
+
+	if token, ok := p.nodes[rel]; ok {
+		return token, nil
+	}
+
+	// 同名页面作为目录的节点
+	for page := range p.pages {
+		if strings.TrimSuffix(page, path.Ext(page)) == rel {
+			return p.page(ctx, page)
+		}
+	}
+
+	entry := p.mapping.Dirs[rel]
+	if entry == nil {
+		entry = p.removedPage(rel)
+	}
+
+	if entry == nil {
+		parent, err := p.dir(ctx, path.Dir(rel))
+		if err != nil {
+			return "", err
+		}
+
+		node, err := p.client.CreateWikiNode(ctx, p.spaceId, parent, "docx", path.Base(rel))
+		if err != nil {
+			return "", err
+		}
+
+		entry = &WikiMappingEntry{NodeToken: derefString(node.NodeToken), ObjToken: derefString(node.ObjToken)}
+		p.mapping.Dirs[rel] = entry
+		p.result.Created = append(p.result.Created, rel+"/")
+	}
+
+	p.nodes[rel] = entry.NodeToken
+	return entry.NodeToken, nil
+}
+
+// removedPage 本地已删除但仍有子页面的同名页面节点，继续作为目录的节点
+func (p *wikiPublisher) removedPage(rel string) *WikiMappingEntry {
+	for page, entry := range p.mapping.Pages {
+		if !p.pages[page] && strings.TrimSuffix(page, path.Ext(page)) == rel {
+			return entry
+		}
+	}
+
+	return nil
+}
+
+// archive 将本地已不存在的页面和目录移动到归档节点下，父节点一并归档时只移动父节点
+// 本地页面及其所有上级目录对应的节点不会被归档
+func (p *wikiPublisher) archive(ctx context.Context) error {
+	live := make(map[string]bool)
+	for rel := range p.pages {
+		for key := wikiPublishKey(rel); key != "."; key = path.Dir(key) {
+			live[key] = true
+		}
+	}
+
+	removed := make(map[string]*WikiMappingEntry)
+	for rel, entry := range p.mapping.Pages {
+		key := strings.TrimSuffix(rel, path.Ext(rel))
+		if p.pages[rel] {
+			continue
+		}
+
+		// 页面已删除但目录中仍有页面时保留节点作为目录
+		if live[key] {
+			entry.Hash = ""
+			p.mapping.Dirs[key] = entry
+			delete(p.mapping.Pages, rel)
+			continue
+		}
+
+		removed[rel] = entry
+	}
+
+	for rel, entry := range p.mapping.Dirs {
+		if !live[rel] {
+			removed[rel+"/"] = entry
+		}
+	}
+
+	keys := make(map[string]bool, len(removed))
+	for rel := range removed {
+		keys[wikiPublishKey(rel)] = true
+	}
+
+	for _, rel := range sortedKeys(removed) {
+		ancestor := false
+		for dir := path.Dir(wikiPublishKey(rel)); dir != "."; dir = path.Dir(dir) {
+			if keys[dir] {
+				ancestor = true
+				break
+			}
+		}
+
+		if !ancestor {
+			if _, err := p.client.MoveWikiNode(ctx, p.spaceId, removed[rel].NodeToken, "", p.opts.archiveNode); err != nil {
+				return err
+			}
+
+			p.result.Archived = append(p.result.Archived, rel)
+		}
+
+		if strings.HasSuffix(rel, "/") {
+			delete(p.mapping.Dirs, strings.TrimSuffix(rel, "/"))
+		} else {
+			delete(p.mapping.Pages, rel)
+		}
+	}
+
+	return nil
+}
+
+// wikiPublishKey 去掉页面的 .md 后缀或目录的 / 后缀
+func wikiPublishKey(rel string) string {
+	if strings.HasSuffix(rel, "/") {
+		return strings.TrimSuffix(rel, "/")
+	}
+
+	return strings.TrimSuffix(rel, path.Ext(rel))
+}